@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 )
 
 var DefaultURL = "http://%s.test.makeplans.net/api/v1"
@@ -26,6 +27,20 @@ type Client struct {
 	// annoying patch for appengine
 	Client   *http.Client
 	Resolver func(string, string) string
+	// Location is the time zone of the account. Makeplans returns some
+	// values as plain dates, these are placed in Location. Defaults to UTC.
+	Location *time.Location
+	// Currency is the ISO 4217 currency of the account, it is applied to
+	// service prices. Prices don't carry a currency and the API has no
+	// account settings to read it from, so it must be set to match the
+	// account. Prices are left without a currency when it is empty.
+	Currency string
+	// Schemas validate custom data before it is sent. They are keyed by
+	// model: "booking", "person", "resource" or "service".
+	Schemas map[string]CustomDataSchema
+	// Drift enables strict mode. It is called for every field in a
+	// response that is unknown to, or doesn't fit, the models.
+	Drift func(Drift)
 }
 
 func New(account string, token string) *Client {
@@ -39,6 +54,13 @@ func New(account string, token string) *Client {
 
 var showResponse = false
 
+func (c *Client) location() *time.Location {
+	if c.Location != nil {
+		return c.Location
+	}
+	return time.UTC
+}
+
 func (c *Client) do(method string, path string, body io.Reader) (*http.Response, error) {
 	var httpCli *http.Client
 
@@ -78,6 +100,7 @@ func (c *Client) Do(method string, path string, body io.Reader) ([]byte, error)
 	if err != nil {
 		return nil, err
 	}
+	c.reportDrift(bs)
 	// FIXME: parseError should happen AFTER endpoints attempt to unmarshal
 	return bs, parseError(bs)
 }
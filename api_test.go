@@ -19,10 +19,12 @@ var ac account
 
 var realClient *Client
 
+// init loads credentials for tests hitting a real account. Without them
+// realClient is nil, use makeplanstest for offline tests instead.
 func init() {
 	bs, err := ioutil.ReadFile("cli/account.json")
 	if err != nil {
-		log.Fatal(err)
+		return
 	}
 	err = json.Unmarshal(bs, &ac)
 	if err != nil {
@@ -149,6 +151,10 @@ func mockServerClient(t *testing.T) (*httptest.Server, *Client) {
 			if r.Method == "DELETE" {
 				w.Write(testProviderDelete)
 			}
+		case ProvidersURL + "2044912747":
+			if r.Method == "DELETE" {
+				w.Write(testProviderDelete395)
+			}
 		case ServiceURL:
 			if r.Method == "GET" {
 				w.Write(testServices)
@@ -167,12 +173,22 @@ func mockServerClient(t *testing.T) (*httptest.Server, *Client) {
 			}
 		case "/services/320/next_available_date":
 			w.Write(testSlotNext)
+		case "/services/321/next_available_date":
+			w.Write([]byte(`[]`))
+		case "/services/427/next_available_date?from=2015-12-10&selected_resources=517":
+			w.Write(testSlotNextResource)
+		case "/services/427/slots?from=2015-12-14&only_free=true&selected_resources=517&to=2015-12-14":
+			w.Write(testSlots501)
 		case "/services/427/slots?from=2015-12-14&to=2015-12-15":
 			fallthrough
 		case "/services/427/slots?from=2015-12-14&selected_resources=501%2C517&to=2015-12-15":
 			w.Write(testSlotsAll)
 		case "/services/427/slots?from=2015-12-14&selected_resources=517&to=2015-12-15":
+			fallthrough
+		case "/resources/517/slots?from=2015-12-14&to=2015-12-15":
 			w.Write(testSlots501)
+		case "/services/427/slots?from=2015-12-14&minimum_free_count=2&only_free=true&to=2015-12-15":
+			w.Write(testSlotsFree)
 		case "/resources/501?from=2015-08-01&to=2015-08-01":
 			w.Write(resourceOpeningResponse)
 		case ResourceURL + "/":
@@ -194,6 +210,8 @@ func mockServerClient(t *testing.T) (*httptest.Server, *Client) {
 				w.Write(testBookings)
 			}
 		case "/bookings/?resource_id=517":
+			fallthrough
+		case "/bookings/?include=person%2Cservice%2Cresource&resource_id=517":
 			w.Write(testBookingsResourceFilter)
 		case EventsURL:
 			w.Write(testEvents)
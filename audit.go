@@ -0,0 +1,267 @@
+package makeplans
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ConflictKind categorizes problems found by AuditBookings
+type ConflictKind string
+
+const (
+	// ConflictOverlap is two bookings sharing a resource at the same time
+	// where only one booking fits. With more capacity overlaps are normal,
+	// see ConflictOverCapacity.
+	ConflictOverlap ConflictKind = "overlap"
+	// ConflictOverCapacity is an interval where a resource holds more
+	// bookings than its capacity. Intervals where every pair of bookings is
+	// already a ConflictOverlap aren't reported again.
+	ConflictOverCapacity ConflictKind = "over_capacity"
+	// ConflictOutsideHours is a booking that doesn't fit within one of the
+	// opening hours of its resource
+	ConflictOutsideHours ConflictKind = "outside_hours"
+)
+
+// Conflict is a single problem found by AuditBookings
+type Conflict struct {
+	Kind       ConflictKind
+	ResourceID int
+	From       time.Time
+	To         time.Time
+	Bookings   []Booking
+	// Booked is the sum of booking counts during the interval
+	Booked   int
+	Capacity int
+}
+
+func (c Conflict) String() string {
+	ids := make([]int, len(c.Bookings))
+	for i, b := range c.Bookings {
+		ids[i] = b.ID
+	}
+	msg := fmt.Sprintf("%s resource %d %s - %s bookings %v", c.Kind,
+		c.ResourceID, c.From.Format(time.RFC3339), c.To.Format(time.RFC3339),
+		ids)
+	if c.Kind == ConflictOverCapacity {
+		msg += fmt.Sprintf(" booked %d of %d", c.Booked, c.Capacity)
+	}
+	return msg
+}
+
+func bookingCount(b Booking) int {
+	if b.Count > 0 {
+		return b.Count
+	}
+	return 1
+}
+
+// AuditBookings checks bookings, ie. from Bookings or BookingAll, against
+// resources and reports overlapping bookings, intervals over capacity and
+// bookings outside opening hours. Bookings that no longer take up capacity,
+// like cancelled ones, are ignored. Opening hours are read in loc, the
+// account time zone, which defaults to UTC. Capacity and opening hours are
+// only checked for bookings of resources that are passed in.
+//
+// Capacity is the lowest of the resource capacity and the BookingCapacity
+// of the booked service, as for Availability. Services that aren't passed
+// in don't limit it.
+func AuditBookings(bookings []Booking, resources []Resource, services []Service, loc *time.Location) ([]Conflict, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	ress := map[int]Resource{}
+	hours := map[int]OpeningHours{}
+	for _, r := range resources {
+		oh, err := r.OpeningHours()
+		if err != nil {
+			return nil, fmt.Errorf("resource %d: %s", r.ID, err)
+		}
+		ress[r.ID] = r
+		hours[r.ID] = oh
+	}
+	svcs := map[int]Service{}
+	for _, svc := range services {
+		svcs[svc.ID] = svc
+	}
+	capacity := func(b Booking) int {
+		return Availability{Service: svcs[b.ServiceID]}.capacity(ress[b.ResourceID])
+	}
+
+	byResource := map[int][]Booking{}
+	var ids []int
+	for _, b := range bookings {
+		if !b.State.Active() || b.BookedFrom == nil || b.BookedTo == nil {
+			continue
+		}
+		if _, ok := byResource[b.ResourceID]; !ok {
+			ids = append(ids, b.ResourceID)
+		}
+		byResource[b.ResourceID] = append(byResource[b.ResourceID], b)
+	}
+	sort.Ints(ids)
+
+	var conflicts []Conflict
+	for _, id := range ids {
+		books := byResource[id]
+		sort.SliceStable(books, func(i, j int) bool {
+			return books[i].BookedFrom.Before(*books[j].BookedFrom)
+		})
+		pairs := overlaps(id, books, capacity)
+		conflicts = append(conflicts, pairs...)
+
+		if _, ok := ress[id]; !ok {
+			continue
+		}
+		// Bookings of different services share the strictest capacity
+		limit := capacity(books[0])
+		for _, b := range books[1:] {
+			if c := capacity(b); c < limit {
+				limit = c
+			}
+		}
+		for _, c := range overCapacity(id, limit, books) {
+			if !reported(c.Bookings, pairs) {
+				conflicts = append(conflicts, c)
+			}
+		}
+		for _, b := range books {
+			if !withinHours(hours[id], b.BookedFrom.In(loc), b.BookedTo.In(loc)) {
+				conflicts = append(conflicts, Conflict{
+					Kind:       ConflictOutsideHours,
+					ResourceID: id,
+					From:       *b.BookedFrom,
+					To:         *b.BookedTo,
+					Bookings:   []Booking{b},
+				})
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// overlaps reports every pair of overlapping bookings where either
+// booking has a capacity of 1. books must be sorted by BookedFrom.
+func overlaps(resourceID int, books []Booking, capacity func(Booking) int) []Conflict {
+	var conflicts []Conflict
+	for i, a := range books {
+		for _, b := range books[i+1:] {
+			if !b.BookedFrom.Before(*a.BookedTo) {
+				break
+			}
+			if capacity(a) > 1 && capacity(b) > 1 {
+				continue
+			}
+			to := *a.BookedTo
+			if b.BookedTo.Before(to) {
+				to = *b.BookedTo
+			}
+			conflicts = append(conflicts, Conflict{
+				Kind:       ConflictOverlap,
+				ResourceID: resourceID,
+				From:       *b.BookedFrom,
+				To:         to,
+				Bookings:   []Booking{a, b},
+				Booked:     bookingCount(a) + bookingCount(b),
+			})
+		}
+	}
+	return conflicts
+}
+
+// reported tells if every pair of books is among the overlaps, so an over
+// capacity conflict of books would report the same double bookings again
+func reported(books []Booking, overlaps []Conflict) bool {
+	if len(books) < 2 {
+		return false
+	}
+	pairs := map[[2]int]bool{}
+	for _, c := range overlaps {
+		pairs[[2]int{c.Bookings[0].ID, c.Bookings[1].ID}] = true
+	}
+	for i, a := range books {
+		for _, b := range books[i+1:] {
+			if !pairs[[2]int{a.ID, b.ID}] && !pairs[[2]int{b.ID, a.ID}] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// overCapacity sweeps the bookings and reports each maximal interval
+// where the booked count exceeds capacity
+func overCapacity(resourceID int, capacity int, books []Booking) []Conflict {
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+	var edges []edge
+	for _, b := range books {
+		edges = append(edges, edge{*b.BookedFrom, bookingCount(b)},
+			edge{*b.BookedTo, -bookingCount(b)})
+	}
+	// Ends sort before starts so back to back bookings don't overlap
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].at.Equal(edges[j].at) {
+			return edges[i].delta < edges[j].delta
+		}
+		return edges[i].at.Before(edges[j].at)
+	})
+
+	var conflicts []Conflict
+	var booked int
+	var current *Conflict
+	for i, e := range edges {
+		booked += e.delta
+		// Only evaluate once all edges at this instant are applied
+		if i+1 < len(edges) && edges[i+1].at.Equal(e.at) {
+			continue
+		}
+		if booked > capacity {
+			if current == nil {
+				current = &Conflict{
+					Kind:       ConflictOverCapacity,
+					ResourceID: resourceID,
+					From:       e.at,
+					Capacity:   capacity,
+				}
+			}
+			if booked > current.Booked {
+				current.Booked = booked
+			}
+			continue
+		}
+		if current != nil {
+			current.To = e.at
+			for _, b := range books {
+				if b.BookedFrom.Before(current.To) && b.BookedTo.After(current.From) {
+					current.Bookings = append(current.Bookings, b)
+				}
+			}
+			conflicts = append(conflicts, *current)
+			current = nil
+		}
+	}
+	return conflicts
+}
+
+// withinHours reports if from and to fall on the same day within a single
+// opening range
+func withinHours(oh OpeningHours, from, to time.Time) bool {
+	y, m, d := from.Date()
+	ty, tm, td := to.Add(-time.Nanosecond).Date()
+	if y != ty || m != tm || d != td {
+		return false
+	}
+	open, close := ClockOf(from), ClockOf(to)
+	if close == 0 {
+		close = 24 * 60
+	}
+	for _, tr := range oh[from.Weekday()] {
+		if open >= tr.Open && close <= tr.Close {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package makeplans
+
+import (
+	"testing"
+	"time"
+)
+
+func testAuditBooking(id int, from, to string, state BookingState) Booking {
+	f, _ := time.Parse(time.RFC3339, from)
+	t, _ := time.Parse(time.RFC3339, to)
+	return Booking{
+		ID:         id,
+		ResourceID: 484,
+		BookedFrom: &f,
+		BookedTo:   &t,
+		Count:      1,
+		State:      state,
+	}
+}
+
+func TestAuditBookings(t *testing.T) {
+	res := Resource{
+		ID:              484,
+		Capacity:        2,
+		OpeningHoursMon: []string{"08:00", "12:00", "13:00", "20:00"},
+	}
+	bookings := []Booking{
+		testAuditBooking(1, "2015-12-14T08:00:00Z", "2015-12-14T10:00:00Z", "confirmed"),
+		testAuditBooking(2, "2015-12-14T09:00:00Z", "2015-12-14T10:00:00Z", "confirmed"),
+		testAuditBooking(3, "2015-12-14T09:30:00Z", "2015-12-14T11:00:00Z", "awaiting_confirmation"),
+		// Ignored
+		testAuditBooking(4, "2015-12-14T09:00:00Z", "2015-12-14T10:00:00Z", "cancelled"),
+		// Back to back is fine
+		testAuditBooking(5, "2015-12-14T11:00:00Z", "2015-12-14T12:00:00Z", "confirmed"),
+		// Over lunch
+		testAuditBooking(6, "2015-12-14T11:30:00Z", "2015-12-14T13:30:00Z", "confirmed"),
+	}
+
+	conflicts, err := AuditBookings(bookings, []Resource{res}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kinds := map[ConflictKind][]Conflict{}
+	for _, c := range conflicts {
+		kinds[c.Kind] = append(kinds[c.Kind], c)
+	}
+
+	// Overlaps are expected with a capacity of 2
+	if e := 0; len(kinds[ConflictOverlap]) != e {
+		t.Errorf("got: %d wanted: %d", len(kinds[ConflictOverlap]), e)
+	}
+
+	over := kinds[ConflictOverCapacity]
+	if e := 1; len(over) != e {
+		t.Fatalf("got: %d wanted: %d", len(over), e)
+	}
+	if e := "over_capacity resource 484 2015-12-14T09:30:00Z - 2015-12-14T10:00:00Z bookings [1 2 3] booked 3 of 2"; over[0].String() != e {
+		t.Errorf("got: %s\nwanted: %s", over[0], e)
+	}
+
+	outside := kinds[ConflictOutsideHours]
+	if e := 1; len(outside) != e {
+		t.Fatalf("got: %d wanted: %d", len(outside), e)
+	}
+	if e := 6; outside[0].Bookings[0].ID != e {
+		t.Errorf("got: %d wanted: %d", outside[0].Bookings[0].ID, e)
+	}
+}
+
+func TestAuditBookings_serviceCapacity(t *testing.T) {
+	res := Resource{ID: 484, Capacity: 20, OpeningHoursMon: []string{"08:00", "20:00"}}
+	var bookings []Booking
+	for id := 1; id <= 3; id++ {
+		b := testAuditBooking(id, "2015-12-14T08:00:00Z", "2015-12-14T09:00:00Z", "confirmed")
+		b.ServiceID = 393
+		bookings = append(bookings, b)
+	}
+
+	// A class of 20 overlaps all the time
+	conflicts, err := AuditBookings(bookings, []Resource{res}, []Service{{ID: 393}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 0; len(conflicts) != e {
+		t.Errorf("got: %v wanted: %d conflicts", conflicts, e)
+	}
+
+	// A private session only fits one booking
+	conflicts, err = AuditBookings(bookings, []Resource{res}, []Service{{ID: 393, BookingCapacity: 1}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[ConflictKind]int{}
+	for _, c := range conflicts {
+		kinds[c.Kind]++
+	}
+	// 1-2, 1-3, 2-3
+	if e := 3; kinds[ConflictOverlap] != e {
+		t.Errorf("got: %d wanted: %d", kinds[ConflictOverlap], e)
+	}
+	// Already reported by the overlaps
+	if e := 0; kinds[ConflictOverCapacity] != e {
+		t.Errorf("got: %d wanted: %d", kinds[ConflictOverCapacity], e)
+	}
+}
+
+func TestAuditBookings_doubleBooked(t *testing.T) {
+	res := Resource{ID: 484, Capacity: 1, OpeningHoursMon: []string{"08:00", "20:00"}}
+	single := testAuditBooking(3, "2015-12-14T12:00:00Z", "2015-12-14T13:00:00Z", "confirmed")
+	single.Count = 2
+	bookings := []Booking{
+		testAuditBooking(1, "2015-12-14T08:00:00Z", "2015-12-14T10:00:00Z", "confirmed"),
+		testAuditBooking(2, "2015-12-14T09:00:00Z", "2015-12-14T11:00:00Z", "confirmed"),
+		// Too many for the resource on its own
+		single,
+	}
+
+	conflicts, err := AuditBookings(bookings, []Resource{res}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The double booking is only reported as an overlap
+	if e := 2; len(conflicts) != e {
+		t.Fatalf("got: %v wanted: %d conflicts", conflicts, e)
+	}
+	if e := "overlap resource 484 2015-12-14T09:00:00Z - 2015-12-14T10:00:00Z bookings [1 2]"; conflicts[0].String() != e {
+		t.Errorf("got: %s\nwanted: %s", conflicts[0], e)
+	}
+	if e := "over_capacity resource 484 2015-12-14T12:00:00Z - 2015-12-14T13:00:00Z bookings [3] booked 2 of 1"; conflicts[1].String() != e {
+		t.Errorf("got: %s\nwanted: %s", conflicts[1], e)
+	}
+}
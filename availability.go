@@ -0,0 +1,271 @@
+package makeplans
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Exception closes a resource between From and To, ie. holidays or sick
+// days. Makeplans calls these resource exception dates.
+type Exception struct {
+	ResourceID int
+	From       time.Time
+	To         time.Time
+}
+
+// Availability computes slots for a service locally from the same data
+// Makeplans uses. Resources should be the resources providing Service.
+type Availability struct {
+	Service    Service
+	Resources  []Resource
+	Exceptions []Exception
+	Bookings   []Booking
+	// Location is the account time zone, opening hours are read in it.
+	// Defaults to UTC.
+	Location *time.Location
+}
+
+// SlotTimeFormat is the layout Makeplans uses for FormattedTimestamp
+var SlotTimeFormat = "Monday, January 2, 2006, 3:04 PM"
+
+// ErrNoInterval is returned when slots are requested for a service
+// without an interval
+var ErrNoInterval = errors.New("service has no interval")
+
+// capacity is the number of concurrent bookings a resource accepts for
+// the service. The lowest of the resource and service capacity wins.
+func (a Availability) capacity(r Resource) int {
+	c := r.Capacity
+	if bc := a.Service.BookingCapacity; bc > 0 && (c == 0 || bc < c) {
+		c = bc
+	}
+	if c == 0 {
+		c = 1
+	}
+	return c
+}
+
+func (a Availability) excepted(resourceID int, from, to time.Time) bool {
+	for _, ex := range a.Exceptions {
+		if ex.ResourceID == resourceID && ex.From.Before(to) && ex.To.After(from) {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceBookings are the active bookings of a resource sorted by start.
+// longest bounds how long before a window an overlapping booking starts.
+type resourceBookings struct {
+	bookings []Booking
+	longest  time.Duration
+}
+
+// bookingsByResource groups the active bookings once, so every window
+// only searches the bookings of its resource
+func (a Availability) bookingsByResource() map[int]*resourceBookings {
+	byResource := map[int]*resourceBookings{}
+	for _, b := range a.Bookings {
+		if !b.State.Active() || b.BookedFrom == nil || b.BookedTo == nil {
+			continue
+		}
+		rb, ok := byResource[b.ResourceID]
+		if !ok {
+			rb = &resourceBookings{}
+			byResource[b.ResourceID] = rb
+		}
+		rb.bookings = append(rb.bookings, b)
+		if d := b.BookedTo.Sub(*b.BookedFrom); d > rb.longest {
+			rb.longest = d
+		}
+	}
+	for _, rb := range byResource {
+		bs := rb.bookings
+		sort.Slice(bs, func(i, j int) bool { return bs[i].BookedFrom.Before(*bs[j].BookedFrom) })
+	}
+	return byResource
+}
+
+// booked counts the bookings overlapping from to
+func (rb *resourceBookings) booked(from, to time.Time) int {
+	if rb == nil {
+		return 0
+	}
+	bs := rb.bookings
+	earliest := from.Add(-rb.longest)
+	lo := sort.Search(len(bs), func(i int) bool { return bs[i].BookedFrom.After(earliest) })
+	hi := sort.Search(len(bs), func(i int) bool { return !bs[i].BookedFrom.Before(to) })
+	var n int
+	for _, b := range bs[lo:hi] {
+		if b.BookedTo.After(from) {
+			n += bookingCount(b)
+		}
+	}
+	return n
+}
+
+func (a Availability) location() *time.Location {
+	if a.Location != nil {
+		return a.Location
+	}
+	return time.UTC
+}
+
+// Slots returns the slots between params.From and params.To, both days
+// inclusive, the way ServiceSlot would.
+func (a Availability) Slots(params SlotParams) ([]Slot, error) {
+	loc := a.location()
+	interval := time.Duration(a.Service.Interval) * time.Minute
+	if interval <= 0 && !params.DayBooking {
+		return nil, ErrNoInterval
+	}
+
+	selected := map[int]bool{}
+	for _, id := range params.SelectedResources {
+		selected[id] = true
+	}
+	type window struct {
+		resource Resource
+		from, to time.Time
+	}
+	var windows []window
+	for _, res := range a.Resources {
+		if len(selected) > 0 && !selected[res.ID] {
+			continue
+		}
+		oh, err := res.OpeningHours()
+		if err != nil {
+			return nil, fmt.Errorf("resource %d: %s", res.ID, err)
+		}
+		y, m, d := params.From.Date()
+		day := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		y, m, d = params.To.Date()
+		last := time.Date(y, m, d, 0, 0, 0, 0, loc)
+		for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+			ranges := oh[day.Weekday()]
+			if params.DayBooking && len(ranges) > 0 {
+				ranges = []TimeRange{{
+					Open:  ranges[0].Open,
+					Close: ranges[len(ranges)-1].Close,
+				}}
+			}
+			for _, tr := range ranges {
+				open, close := tr.Open.On(day), tr.Close.On(day)
+				if params.DayBooking {
+					windows = append(windows, window{res, open, close})
+					continue
+				}
+				for t := open; !t.Add(interval).After(close); t = t.Add(interval) {
+					windows = append(windows, window{res, t, t.Add(interval)})
+				}
+			}
+		}
+	}
+
+	booked := a.bookingsByResource()
+	var slots []Slot
+	index := map[int64]int{}
+	for _, w := range windows {
+		if a.excepted(w.resource.ID, w.from, w.to) {
+			continue
+		}
+		key := w.from.Unix()
+		i, ok := index[key]
+		if !ok {
+			from, to := w.from, w.to
+			slots = append(slots, Slot{
+				Timestamp:             &from,
+				TimestampEnd:          &to,
+				FormattedTimestamp:    from.Format(SlotTimeFormat),
+				FormattedTimestampEnd: to.Format(SlotTimeFormat),
+			})
+			i = len(slots) - 1
+			index[key] = i
+		}
+		slot := &slots[i]
+		slot.OpenResources = append(slot.OpenResources, w.resource.ID)
+		free := a.capacity(w.resource) - booked[w.resource.ID].booked(w.from, w.to)
+		if free > 0 {
+			slot.Free += free
+			slot.AvailableResources = append(slot.AvailableResources,
+				w.resource.ID)
+		}
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].Timestamp.Before(*slots[j].Timestamp)
+	})
+	if !params.OnlyFree {
+		return slots, nil
+	}
+	min := params.MinimumFreeCount
+	if min == 0 {
+		min = 1
+	}
+	free := slots[:0]
+	for _, slot := range slots {
+		if slot.Free >= min {
+			free = append(free, slot)
+		}
+	}
+	return free, nil
+}
+
+// SlotDiff describes a slot where two slot lists disagree. A nil Local or
+// Remote means the slot is missing from that list.
+type SlotDiff struct {
+	Timestamp time.Time
+	Local     *Slot
+	Remote    *Slot
+}
+
+// DiffSlots compares locally computed slots against slots returned by
+// ServiceSlot. Slots are matched by Timestamp and compared on TimestampEnd
+// and Free.
+func DiffSlots(local, remote []Slot) []SlotDiff {
+	byTime := map[int64]*SlotDiff{}
+	var keys []int64
+	add := func(slots []Slot, isLocal bool) {
+		for i := range slots {
+			slot := &slots[i]
+			if slot.Timestamp == nil {
+				continue
+			}
+			key := slot.Timestamp.Unix()
+			d, ok := byTime[key]
+			if !ok {
+				d = &SlotDiff{Timestamp: *slot.Timestamp}
+				byTime[key] = d
+				keys = append(keys, key)
+			}
+			if isLocal {
+				d.Local = slot
+			} else {
+				d.Remote = slot
+			}
+		}
+	}
+	add(local, true)
+	add(remote, false)
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	var diffs []SlotDiff
+	for _, key := range keys {
+		d := byTime[key]
+		if d.Local != nil && d.Remote != nil && d.Local.Free == d.Remote.Free &&
+			sameTime(d.Local.TimestampEnd, d.Remote.TimestampEnd) {
+			continue
+		}
+		diffs = append(diffs, *d)
+	}
+	return diffs
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
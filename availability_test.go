@@ -0,0 +1,162 @@
+package makeplans
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func testAvailability(t *testing.T) Availability {
+	var bookings []wrapBooking
+	err := json.Unmarshal(testBookingsResourceFilter, &bookings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc := time.FixedZone("CST", -6*60*60)
+	hours := []string{"12:00", "14:00"}
+	res := func(id int) Resource {
+		return Resource{
+			ID:              id,
+			Capacity:        1,
+			OpeningHoursMon: hours,
+			OpeningHoursTue: hours,
+		}
+	}
+	return Availability{
+		Service: Service{
+			ID:              427,
+			BookingCapacity: 10,
+			Interval:        60,
+		},
+		Resources: []Resource{res(501), res(517)},
+		Exceptions: []Exception{{
+			ResourceID: 517,
+			From:       time.Date(2015, 12, 15, 0, 0, 0, 0, loc),
+			To:         time.Date(2015, 12, 16, 0, 0, 0, 0, loc),
+		}},
+		Bookings: []Booking{bookings[0].Booking},
+		Location: loc,
+	}
+}
+
+func TestAvailability_slots(t *testing.T) {
+	a := testAvailability(t)
+	layout := "2006-01-02"
+	from, _ := time.Parse(layout, "2015-12-14")
+	to, _ := time.Parse(layout, "2015-12-15")
+
+	local, err := a.Slots(SlotParams{From: from, To: to})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wr []slotWrap
+	err = json.Unmarshal(testSlotsAll, &wr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := make([]Slot, len(wr))
+	for i, w := range wr {
+		remote[i] = w.Slot
+	}
+
+	if diffs := DiffSlots(local, remote); len(diffs) > 0 {
+		for _, d := range diffs {
+			t.Errorf("mismatch at %s: local %v remote %v", d.Timestamp,
+				d.Local, d.Remote)
+		}
+	}
+
+	if e := "Monday, December 14, 2015, 12:00 PM"; local[0].FormattedTimestamp != e {
+		t.Errorf("got: %s wanted: %s", local[0].FormattedTimestamp, e)
+	}
+
+	if e := 2; len(local[0].OpenResources) != e {
+		t.Errorf("got: %d wanted: %d", len(local[0].OpenResources), e)
+	}
+
+	if e := 1; len(local[0].AvailableResources) != e {
+		t.Errorf("got: %d wanted: %d", len(local[0].AvailableResources), e)
+	}
+}
+
+func TestAvailability_onlyFree(t *testing.T) {
+	a := testAvailability(t)
+	layout := "2006-01-02"
+	from, _ := time.Parse(layout, "2015-12-14")
+	to, _ := time.Parse(layout, "2015-12-15")
+
+	slots, err := a.Slots(SlotParams{
+		From:             from,
+		To:               to,
+		OnlyFree:         true,
+		MinimumFreeCount: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e := 1; len(slots) != e {
+		t.Fatalf("got: %d wanted: %d", len(slots), e)
+	}
+
+	if e := 2; slots[0].Free != e {
+		t.Errorf("got: %d wanted: %d", slots[0].Free, e)
+	}
+
+	slots, err = a.Slots(SlotParams{
+		From:              from,
+		To:                from,
+		SelectedResources: []int{517},
+		OnlyFree:          true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e := 1; len(slots) != e {
+		t.Fatalf("got: %d wanted: %d", len(slots), e)
+	}
+
+	a.Service.Interval = 0
+	if _, err := a.Slots(SlotParams{From: from, To: to}); err != ErrNoInterval {
+		t.Errorf("got: %v wanted: %s", err, ErrNoInterval)
+	}
+}
+
+func TestAvailability_booked(t *testing.T) {
+	at := func(day, hour int) *time.Time {
+		t := time.Date(2015, 12, day, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	booking := func(resourceID int, from, to *time.Time, state BookingState) Booking {
+		return Booking{ResourceID: resourceID, BookedFrom: from, BookedTo: to, State: state}
+	}
+	a := Availability{
+		Service: Service{Interval: 60},
+		Resources: []Resource{{ID: 484, Capacity: 2,
+			OpeningHoursMon: []string{"08:00", "12:00"}}},
+		// Unsorted, the first starts the day before
+		Bookings: []Booking{
+			booking(484, at(14, 10), at(14, 11), BookingConfirmed),
+			booking(484, at(13, 20), at(14, 10), BookingConfirmed),
+			booking(484, at(14, 7), at(14, 8), BookingConfirmed),
+			booking(484, at(14, 11), at(14, 12), BookingCancelled),
+			booking(485, at(14, 11), at(14, 12), BookingConfirmed),
+		},
+	}
+	from := time.Date(2015, 12, 14, 0, 0, 0, 0, time.UTC)
+	slots, err := a.Slots(SlotParams{From: from, To: from})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := []int{1, 1, 1, 2}
+	if len(slots) != len(e) {
+		t.Fatalf("got: %d wanted: %d", len(slots), len(e))
+	}
+	for i := range e {
+		if slots[i].Free != e[i] {
+			t.Errorf("%s got: %d wanted: %d", slots[i].Timestamp, slots[i].Free, e[i])
+		}
+	}
+}
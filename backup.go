@@ -0,0 +1,246 @@
+package makeplans
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// SnapshotVersion is the snapshot format written by WriteSnapshot
+const SnapshotVersion = 1
+
+// Snapshot is the state of an account at one point in time
+type Snapshot struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// Account names the source of the snapshot, it is informational
+	Account string `json:"account,omitempty"`
+
+	Services  []Service  `json:"services"`
+	Resources []Resource `json:"resources"`
+	Providers []Provider `json:"providers"`
+	People    []Person   `json:"people"`
+	Events    []Event    `json:"events"`
+	Bookings  []Booking  `json:"bookings"`
+	// SkippedServices are inactive services still referenced by providers,
+	// events or bookings. Makeplans only lists active services, so they
+	// are missing from Services.
+	SkippedServices []int `json:"skipped_services,omitempty"`
+}
+
+// Backup reads everything in the account. Resources include their opening
+// hours and bookings come from BookingAll. Inactive services can't be read
+// and are listed in SkippedServices.
+func Backup(api API) (*Snapshot, error) {
+	s := &Snapshot{Version: SnapshotVersion, CreatedAt: time.Now()}
+	var err error
+	if s.Services, err = api.Services(); err != nil {
+		return nil, fmt.Errorf("services: %s", err)
+	}
+	if s.Resources, err = api.Resources(); err != nil {
+		return nil, fmt.Errorf("resources: %s", err)
+	}
+	if s.Providers, err = api.Providers(); err != nil {
+		return nil, fmt.Errorf("providers: %s", err)
+	}
+	if s.People, err = api.People(); err != nil {
+		return nil, fmt.Errorf("people: %s", err)
+	}
+	if s.Events, err = api.Events(); err != nil {
+		return nil, fmt.Errorf("events: %s", err)
+	}
+	if s.Bookings, err = api.BookingAll(); err != nil {
+		return nil, fmt.Errorf("bookings: %s", err)
+	}
+	s.SkippedServices = s.missingServices()
+	return s, nil
+}
+
+// missingServices returns the referenced services that aren't in the
+// snapshot
+func (s *Snapshot) missingServices() []int {
+	known := map[int]bool{0: true}
+	for _, svc := range s.Services {
+		known[svc.ID] = true
+	}
+	var ids []int
+	add := func(id int) {
+		if !known[id] {
+			known[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, p := range s.Providers {
+		add(p.ServiceID)
+	}
+	for _, e := range s.Events {
+		add(e.ServiceID)
+	}
+	for _, b := range s.Bookings {
+		add(b.ServiceID)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// SnapshotCounts is the number of records of each kind
+type SnapshotCounts struct {
+	Services  int `json:"services"`
+	Resources int `json:"resources"`
+	Providers int `json:"providers"`
+	People    int `json:"people"`
+	Events    int `json:"events"`
+	Bookings  int `json:"bookings"`
+}
+
+func (c SnapshotCounts) String() string {
+	return fmt.Sprintf("%d services, %d resources, %d providers, %d people, %d events, %d bookings",
+		c.Services, c.Resources, c.Providers, c.People, c.Events, c.Bookings)
+}
+
+// Counts of the records in the snapshot
+func (s *Snapshot) Counts() SnapshotCounts {
+	return SnapshotCounts{
+		Services:  len(s.Services),
+		Resources: len(s.Resources),
+		Providers: len(s.Providers),
+		People:    len(s.People),
+		Events:    len(s.Events),
+		Bookings:  len(s.Bookings),
+	}
+}
+
+// snapshotHeader is the first line of a snapshot
+type snapshotHeader struct {
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	Account   string         `json:"account,omitempty"`
+	Counts    SnapshotCounts `json:"counts"`
+	// SkippedServices aren't counted, the snapshot has no record of them
+	SkippedServices []int `json:"skipped_services,omitempty"`
+}
+
+// snapshotLine holds one record, wrapped the way the API wraps it
+type snapshotLine struct {
+	Snapshot *snapshotHeader `json:"snapshot,omitempty"`
+	Service  *Service        `json:"service,omitempty"`
+	Resource *Resource       `json:"resource,omitempty"`
+	Provider *Provider       `json:"provider,omitempty"`
+	Person   *Person         `json:"person,omitempty"`
+	Event    *Event          `json:"event,omitempty"`
+	Booking  *Booking        `json:"booking,omitempty"`
+}
+
+// WriteSnapshot writes s as JSON lines. The first line describes the
+// snapshot, every following line is one record keyed by its kind:
+//
+//	{"snapshot":{"version":1,"created_at":"...","counts":{...}}}
+//	{"service":{"id":393,"title":"Yoga",...}}
+//	{"booking":{"id":12,"service_id":393,...}}
+func WriteSnapshot(w io.Writer, s *Snapshot) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(snapshotLine{Snapshot: &snapshotHeader{
+		Version:   SnapshotVersion,
+		CreatedAt: s.CreatedAt,
+		Account:   s.Account,
+		Counts:    s.Counts(),
+
+		SkippedServices: s.SkippedServices,
+	}}); err != nil {
+		return err
+	}
+	for i := range s.Services {
+		if err := enc.Encode(snapshotLine{Service: &s.Services[i]}); err != nil {
+			return err
+		}
+	}
+	for i := range s.Resources {
+		if err := enc.Encode(snapshotLine{Resource: &s.Resources[i]}); err != nil {
+			return err
+		}
+	}
+	for i := range s.Providers {
+		if err := enc.Encode(snapshotLine{Provider: &s.Providers[i]}); err != nil {
+			return err
+		}
+	}
+	for i := range s.People {
+		if err := enc.Encode(snapshotLine{Person: &s.People[i]}); err != nil {
+			return err
+		}
+	}
+	for i := range s.Events {
+		if err := enc.Encode(snapshotLine{Event: &s.Events[i]}); err != nil {
+			return err
+		}
+	}
+	for i := range s.Bookings {
+		if err := enc.Encode(snapshotLine{Booking: &s.Bookings[i]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadSnapshot reads a snapshot written by WriteSnapshot. Snapshots from a
+// newer format or missing records, ie. a truncated file, are rejected.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	sc := bufio.NewScanner(r)
+	// Records with lots of custom data outgrow the default line length
+	sc.Buffer(nil, 16<<20)
+	var header *snapshotHeader
+	s := &Snapshot{}
+	for n := 1; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var line snapshotLine
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+		if header == nil {
+			if line.Snapshot == nil {
+				return nil, fmt.Errorf("line %d: not a makeplans snapshot", n)
+			}
+			header = line.Snapshot
+			if header.Version > SnapshotVersion {
+				return nil, fmt.Errorf("snapshot version %d is newer than version %d", header.Version, SnapshotVersion)
+			}
+			s.Version = header.Version
+			s.CreatedAt = header.CreatedAt
+			s.Account = header.Account
+			s.SkippedServices = header.SkippedServices
+			continue
+		}
+		switch {
+		case line.Service != nil:
+			s.Services = append(s.Services, *line.Service)
+		case line.Resource != nil:
+			s.Resources = append(s.Resources, *line.Resource)
+		case line.Provider != nil:
+			s.Providers = append(s.Providers, *line.Provider)
+		case line.Person != nil:
+			s.People = append(s.People, *line.Person)
+		case line.Event != nil:
+			s.Events = append(s.Events, *line.Event)
+		case line.Booking != nil:
+			s.Bookings = append(s.Bookings, *line.Booking)
+		default:
+			return nil, fmt.Errorf("line %d: unknown record", n)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("empty snapshot")
+	}
+	if got := s.Counts(); got != header.Counts {
+		return nil, fmt.Errorf("incomplete snapshot, found %s of %s", got, header.Counts)
+	}
+	return s, nil
+}
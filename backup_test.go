@@ -0,0 +1,96 @@
+package makeplans_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drewwells/makeplans"
+	"github.com/drewwells/makeplans/makeplanstest"
+)
+
+func TestBackup(t *testing.T) {
+	srv := makeplanstest.NewServer()
+	defer srv.Close()
+	from := time.Date(2015, 12, 14, 9, 0, 0, 0, time.UTC)
+	srv.AddService(makeplans.Service{ID: 393, Title: "Yoga", Active: true, Interval: 60})
+	srv.AddResource(makeplans.Resource{ID: 484, Title: "Studio", Capacity: 10,
+		OpeningHoursMon: []string{"08:00", "16:00"}})
+	srv.AddProvider(makeplans.Provider{ID: 1, ServiceID: 393, ResourceID: 484})
+	srv.AddPerson(makeplans.Person{ID: 7, Name: "Jane", Email: "jane@example.com",
+		CustomData: makeplans.CustomData{"level": "3"}})
+	srv.AddEvent(makeplans.Event{ID: 3, Title: "Open day", Start: &from})
+	srv.AddBooking(makeplans.Booking{ID: 12, ServiceID: 393, ResourceID: 484,
+		PersonID: 7, BookedFrom: &from, State: makeplans.BookingConfirmed})
+	// Inactive services aren't listed, their bookings still are
+	srv.AddService(makeplans.Service{ID: 401, Title: "Chiropractor", Interval: 30})
+	srv.AddBooking(makeplans.Booking{ID: 13, ServiceID: 401, ResourceID: 484,
+		BookedFrom: &from, State: makeplans.BookingCancelled})
+
+	s, err := makeplans.Backup(srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Account = "gym"
+	if e := makeplans.SnapshotVersion; s.Version != e {
+		t.Errorf("got: %d wanted: %d", s.Version, e)
+	}
+	if e := "1 services, 1 resources, 1 providers, 1 people, 1 events, 2 bookings"; s.Counts().String() != e {
+		t.Errorf("got: %s wanted: %s", s.Counts(), e)
+	}
+	if e := []int{401}; !reflect.DeepEqual(s.SkippedServices, e) {
+		t.Errorf("got: %v wanted: %v", s.SkippedServices, e)
+	}
+
+	var buf bytes.Buffer
+	if err := makeplans.WriteSnapshot(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if e := 8; len(lines) != e {
+		t.Fatalf("got: %d wanted: %d", len(lines), e)
+	}
+	if e := `{"snapshot":{"version":1,`; !strings.HasPrefix(lines[0], e) {
+		t.Errorf("got: %s wanted prefix: %s", lines[0], e)
+	}
+	if e := `"skipped_services":[401]}}`; !strings.HasSuffix(lines[0], e) {
+		t.Errorf("got: %s wanted suffix: %s", lines[0], e)
+	}
+	if e := `{"service":{"id":393,`; !strings.HasPrefix(lines[1], e) {
+		t.Errorf("got: %s wanted prefix: %s", lines[1], e)
+	}
+
+	read, err := makeplans.ReadSnapshot(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !read.CreatedAt.Equal(s.CreatedAt) {
+		t.Errorf("got: %s wanted: %s", read.CreatedAt, s.CreatedAt)
+	}
+	read.CreatedAt = s.CreatedAt
+	if !reflect.DeepEqual(read, s) {
+		t.Errorf("got: %+v\nwanted: %+v", read, s)
+	}
+
+	truncated := strings.Join(lines[:7], "\n")
+	_, err = makeplans.ReadSnapshot(strings.NewReader(truncated))
+	if e := "incomplete snapshot, found 1 services, 1 resources, 1 providers, 1 people, 1 events, 1 bookings of 1 services, 1 resources, 1 providers, 1 people, 1 events, 2 bookings"; err == nil || err.Error() != e {
+		t.Errorf("got: %v wanted: %s", err, e)
+	}
+
+	_, err = makeplans.ReadSnapshot(strings.NewReader(`{"snapshot":{"version":2}}`))
+	if e := "snapshot version 2 is newer than version 1"; err == nil || err.Error() != e {
+		t.Errorf("got: %v wanted: %s", err, e)
+	}
+	_, err = makeplans.ReadSnapshot(strings.NewReader(`{"service":{"id":1}}`))
+	if e := "line 1: not a makeplans snapshot"; err == nil || err.Error() != e {
+		t.Errorf("got: %v wanted: %s", err, e)
+	}
+
+	srv.Close()
+	if _, err := makeplans.Backup(srv.Client()); err == nil || !strings.HasPrefix(err.Error(), "services: ") {
+		t.Errorf("got: %v wanted: services error", err)
+	}
+}
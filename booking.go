@@ -7,31 +7,51 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Booking struct {
-	BookedFrom    *time.Time             `json:"booked_from,omitempty"`
-	BookedTo      *time.Time             `json:"booked_to,omitempty"`
-	CustomData    map[string]interface{} `json:"custom_data,omitempty"`
-	Count         int                    `json:"count,omitempty"`
-	ExpiresAt     *time.Time             `json:"expires_at,omitempty"`
-	ExternalID    string                 `json:"external_id,omitempty"`
-	ID            int                    `json:"id,omitempty"`
-	Notes         string                 `json:"notes,omitempty"`
-	PersonID      int                    `json:"person_id,omitempty"`
-	ResourceID    int                    `json:"resource_id,omitempty"`
-	ServiceID     int                    `json:"service_id,omitempty"`
-	PublicBooking bool                   `json:"public_booking,omitempty"`
-	State         string                 `json:"state,omitempty"`
-	CreatedAt     *time.Time             `json:"created_at,omitempty"`
-	UpdatedAt     *time.Time             `json:"updated_at,omitempty"`
+	BookedFrom    *time.Time   `json:"booked_from,omitempty"`
+	BookedTo      *time.Time   `json:"booked_to,omitempty"`
+	CollectionID  string       `json:"collection_id,omitempty"`
+	CustomData    CustomData   `json:"custom_data,omitempty"`
+	Count         int          `json:"count,omitempty"`
+	EventID       int          `json:"event_id,omitempty"`
+	ExpiresAt     *time.Time   `json:"expires_at,omitempty"`
+	ExternalID    string       `json:"external_id,omitempty"`
+	ID            int          `json:"id,omitempty"`
+	Notes         string       `json:"notes,omitempty"`
+	PersonID      int          `json:"person_id,omitempty"`
+	ResourceID    int          `json:"resource_id,omitempty"`
+	ServiceID     int          `json:"service_id,omitempty"`
+	PublicBooking bool         `json:"public_booking,omitempty"`
+	RemindedAt    *time.Time   `json:"reminded_at,omitempty"`
+	ReminderAt    *time.Time   `json:"reminder_at,omitempty"`
+	State         BookingState `json:"state,omitempty"`
+	CreatedAt     *time.Time   `json:"created_at,omitempty"`
+	UpdatedAt     *time.Time   `json:"updated_at,omitempty"`
+
+	// Person, Service and Resource are embedded by Makeplans when
+	// requested with BookingParams.Include. Service and Resource are
+	// often abbreviated to id and title. They are read only and not sent
+	// by MakeBooking and BookingUpdate.
+	Person   *Person   `json:"person,omitempty"`
+	Service  *Service  `json:"service,omitempty"`
+	Resource *Resource `json:"resource,omitempty"`
 }
 
 type wrapBooking struct {
 	Booking Booking `json:"booking"`
 }
 
+// writeBooking wraps b for POST and PUT bodies. The objects embedded by
+// Include are read only and left out.
+func writeBooking(b Booking) wrapBooking {
+	b.Person, b.Service, b.Resource = nil, nil, nil
+	return wrapBooking{Booking: b}
+}
+
 var (
 	// BookingURL defines the entrypoint for all bookings
 	BookingURL = "/bookings/"
@@ -40,6 +60,13 @@ var (
 	ErrBookingCapacityLimit = errors.New("error resource_id: Not available for booking at this timeerror count: More than maximum count per booking")
 )
 
+// Include names for BookingParams.Include
+const (
+	IncludePerson   = "person"
+	IncludeService  = "service"
+	IncludeResource = "resource"
+)
+
 type BookingParams struct {
 	ServiceID    int
 	EventID      int
@@ -50,6 +77,9 @@ type BookingParams struct {
 	End          time.Time
 	Since        time.Time
 	CollectionID string
+	// Include embeds related objects in every booking, avoiding a lookup
+	// per booking. See IncludePerson, IncludeService and IncludeResource.
+	Include []string
 }
 
 // Booking returns just booking matching the passed id
@@ -85,6 +115,15 @@ func (c *Client) Bookings(params BookingParams) ([]Booking, error) {
 	if len(params.ExternalID) > 0 {
 		v.Add("external_id", params.ExternalID)
 	}
+	if len(params.CollectionID) > 0 {
+		v.Add("collection_id", params.CollectionID)
+	}
+	if len(params.Include) > 0 {
+		v.Set("include", strings.Join(params.Include, ","))
+	}
+	if !params.Since.IsZero() {
+		v.Set("since", params.Since.Format(time.RFC3339))
+	}
 	layout := "2006-01-02"
 	if !params.Start.IsZero() {
 		v.Set("start", params.Start.Format(layout))
@@ -137,9 +176,13 @@ func (c *Client) BookingAll() ([]Booking, error) {
 }
 
 func (c *Client) MakeBooking(b Booking) (ret Booking, err error) {
+	err = c.validateCustomData("booking", b.CustomData)
+	if err != nil {
+		return
+	}
 	// Verify time slot is available
 	b.PublicBooking = true
-	bs, err := json.Marshal(wrapBooking{Booking: b})
+	bs, err := json.Marshal(writeBooking(b))
 	if err != nil {
 		return
 	}
@@ -181,8 +224,12 @@ func (c *Client) mutateBooking(action string, id int) (ret Booking, err error) {
 }
 
 func (c *Client) BookingUpdate(b Booking) (Booking, error) {
+	err := c.validateCustomData("booking", b.CustomData)
+	if err != nil {
+		return Booking{}, err
+	}
 
-	bs, err := json.Marshal(wrapBooking{Booking: b})
+	bs, err := json.Marshal(writeBooking(b))
 	if err != nil {
 		return Booking{}, err
 	}
@@ -1,6 +1,9 @@
 package makeplans
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -90,7 +93,7 @@ func TestBooking_one(t *testing.T) {
 	if book.ID != id {
 		t.Errorf("got: %d wanted: %d", book.ID, id)
 	}
-	if e := "deleted"; book.State != e {
+	if e := BookingDeleted; book.State != e {
 		t.Errorf("got: %s wanted: %s", book.State, e)
 	}
 
@@ -137,6 +140,26 @@ func TestBooking_list(t *testing.T) {
 	if e := 12389; book.PersonID != e {
 		t.Errorf("got: %d wanted: %d", book.PersonID, e)
 	}
+
+	params.Include = []string{IncludePerson, IncludeService, IncludeResource}
+	books, err = client.Bookings(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book = books[0]
+	if book.Person == nil || book.Service == nil || book.Resource == nil {
+		t.Fatalf("expected embedded objects got: %+v", book)
+	}
+	if e := "Test User"; book.Person.Name != e {
+		t.Errorf("got: %s wanted: %s", book.Person.Name, e)
+	}
+	if e := "Kickboxing"; book.Service.Title != e {
+		t.Errorf("got: %s wanted: %s", book.Service.Title, e)
+	}
+	if e := "jill bob"; book.Resource.Title != e {
+		t.Errorf("got: %s wanted: %s", book.Resource.Title, e)
+	}
 }
 
 var testBookingSuccess = []byte(`{"booking":{"booked_from":"2015-11-10T08:00:00-06:00","booked_to":"2015-11-10T09:00:00-06:00","collection_id":null,"count":1,"created_at":"2015-11-07T09:09:32-06:00","custom_data":{},"event_id":null,"expires_at":null,"external_id":null,"id":410372,"notes":"Very handsome client","person_id":null,"resource_id":484,"service_id":394,"state":"confirmed","updated_at":"2015-11-07T09:09:32-06:00","resource":{"id":484,"title":"Calendar"},"service":{"id":394,"title":"Cross Fit Type"}}}`)
@@ -237,8 +260,44 @@ func TestBooking_cancel(t *testing.T) {
 		t.Errorf("got: %d wanted: %d", book.ID, e)
 	}
 
-	if e := "cancelled"; book.State != e {
+	if e := BookingCancelled; book.State != e {
 		t.Errorf("got: %s wanted: %s", book.State, e)
 	}
 
 }
+
+func TestBooking_updateIncluded(t *testing.T) {
+	var body map[string]map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{"booking":{"id":1}}`))
+	}))
+	defer ts.Close()
+	client := &Client{URL: ts.URL, Resolver: testResolver}
+
+	// A booking fetched with Include is sent back without the embedded objects
+	b := Booking{ID: 1, PersonID: 7, ServiceID: 393, ResourceID: 484,
+		Person:   &Person{ID: 7, Name: "Jane"},
+		Service:  &Service{ID: 393, Title: "Yoga"},
+		Resource: &Resource{ID: 484, Title: "Studio"},
+	}
+	if _, err := client.BookingUpdate(b); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"person", "service", "resource"} {
+		if v, ok := body["booking"][name]; ok {
+			t.Errorf("%s got: %v wanted: omitted", name, v)
+		}
+	}
+	if e := 7.0; body["booking"]["person_id"] != e {
+		t.Errorf("got: %v wanted: %v", body["booking"]["person_id"], e)
+	}
+	if _, err := client.MakeBooking(b); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := body["booking"]["person"]; ok {
+		t.Errorf("got: %v wanted: omitted", v)
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/drewwells/makeplans"
+)
+
+const minutesPerDay = 24 * 60
+
+// ANSI colours of the agenda
+const (
+	colorReset = "\x1b[0m"
+	colorDim   = "\x1b[2m"
+)
+
+var stateColors = map[makeplans.BookingState]string{
+	makeplans.BookingConfirmed:            "\x1b[32m",
+	makeplans.BookingAwaitingConfirmation: "\x1b[33m",
+	makeplans.BookingUnverified:           "\x1b[36m",
+}
+
+// agendaColumn is one resource on one day, by the minute
+type agendaColumn struct {
+	title    string
+	day      time.Time
+	capacity int
+	open     [minutesPerDay]bool
+	booked   [minutesPerDay]int
+	bookings []agendaBooking
+}
+
+// agendaBooking is a booking clipped to the day in minutes
+type agendaBooking struct {
+	makeplans.Booking
+	from, to int
+}
+
+// agenda prints the bookings of each resource as a grid, with free time
+// from the opening hours
+func (a *app) agenda(args []string) error {
+	fs := a.flags("agenda")
+	date := a.today()
+	a.timeVar(fs, &date, "date", "day to show")
+	week := fs.Bool("week", false, "show the week of -date, one grid per resource")
+	resourceID := fs.Int("resource", 0, "only show this resource")
+	step := fs.Duration("step", 30*time.Minute, "length of a row")
+	width := fs.Int("width", 16, "width of a column")
+	color := fs.String("color", "auto", "colour bookings by state: auto, always or never")
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 0 {
+		return fmt.Errorf("unexpected arguments %v", pos)
+	}
+	if *step < time.Minute || *width < 4 {
+		return fmt.Errorf("-step must be at least 1m and -width at least 4")
+	}
+	switch *color {
+	case "auto", "always", "never":
+	default:
+		return fmt.Errorf("invalid -color %q", *color)
+	}
+
+	y, m, d := date.In(a.loc).Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, a.loc)
+	days := 1
+	if *week {
+		// Weeks start on Monday
+		start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+		days = 7
+	}
+
+	resources, err := a.api.Resources()
+	if err != nil {
+		return err
+	}
+	bookings, err := a.api.Bookings(makeplans.BookingParams{
+		ResourceID: *resourceID,
+		Start:      start,
+		End:        start.AddDate(0, 0, days-1),
+		Include:    []string{makeplans.IncludePerson},
+	})
+	if err != nil {
+		return err
+	}
+
+	g := grid{
+		step:  int(*step / time.Minute),
+		width: *width,
+		color: *color == "always" || *color == "auto" && a.terminal(),
+	}
+	var cols []*agendaColumn
+	for _, r := range resources {
+		if *resourceID > 0 && r.ID != *resourceID {
+			continue
+		}
+		for i := 0; i < days; i++ {
+			col, err := a.column(r, start.AddDate(0, 0, i), bookings)
+			if err != nil {
+				return err
+			}
+			cols = append(cols, col)
+		}
+	}
+	if len(cols) == 0 {
+		return fmt.Errorf("no resources")
+	}
+
+	if !*week {
+		fmt.Fprintln(a.out, start.Format("Monday 2006-01-02"))
+		g.print(a.out, cols, func(c *agendaColumn) string { return c.title })
+		return nil
+	}
+	for i := 0; i < len(cols); i += days {
+		if i > 0 {
+			fmt.Fprintln(a.out)
+		}
+		fmt.Fprintf(a.out, "%s, week of %s\n", cols[i].title, start.Format("2006-01-02"))
+		g.print(a.out, cols[i:i+days], func(c *agendaColumn) string {
+			return c.day.Format("Mon 02")
+		})
+	}
+	return nil
+}
+
+// terminal reports if the output is a terminal wanting colours
+func (a *app) terminal() bool {
+	if a.getenv != nil && len(a.getenv("NO_COLOR")) > 0 {
+		return false
+	}
+	f, ok := a.out.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// column lays out the active bookings of a resource on day
+func (a *app) column(r makeplans.Resource, day time.Time, bookings []makeplans.Booking) (*agendaColumn, error) {
+	col := &agendaColumn{title: r.Title, day: day, capacity: r.Capacity}
+	if col.capacity < 1 {
+		col.capacity = 1
+	}
+	if len(col.title) == 0 {
+		col.title = fmt.Sprintf("resource %d", r.ID)
+	}
+	oh, err := r.OpeningHours()
+	if err != nil {
+		return nil, fmt.Errorf("resource %d: %s", r.ID, err)
+	}
+	for _, tr := range oh[day.Weekday()] {
+		for m := tr.Open; m < tr.Close && m < minutesPerDay; m++ {
+			col.open[m] = true
+		}
+	}
+	for _, b := range bookings {
+		if b.ResourceID != r.ID || !b.State.Active() || b.BookedFrom == nil {
+			continue
+		}
+		from, to := *b.BookedFrom, *b.BookedFrom
+		if b.BookedTo != nil {
+			to = *b.BookedTo
+		}
+		// Skip bookings outside the day, keeping empty ones at midnight
+		if !from.Before(day.AddDate(0, 0, 1)) || !to.After(day) && from.Before(day) {
+			continue
+		}
+		ab := agendaBooking{Booking: b, from: clip(from.Sub(day)), to: clip(to.Sub(day))}
+		if ab.to <= ab.from {
+			// Show bookings without length for a minute
+			ab.to = ab.from + 1
+		}
+		count := b.Count
+		if count < 1 {
+			count = 1
+		}
+		for m := ab.from; m < ab.to; m++ {
+			col.booked[m] += count
+		}
+		col.bookings = append(col.bookings, ab)
+	}
+	return col, nil
+}
+
+// clip converts an offset from midnight to minutes within the day
+func clip(d time.Duration) int {
+	m := int(d / time.Minute)
+	switch {
+	case m < 0:
+		return 0
+	case m >= minutesPerDay:
+		return minutesPerDay
+	}
+	return m
+}
+
+// free lists the periods with room for another booking
+func (c *agendaColumn) free() []makeplans.TimeRange {
+	var ranges []makeplans.TimeRange
+	for m := 0; m < minutesPerDay; m++ {
+		if !c.open[m] || c.booked[m] >= c.capacity {
+			continue
+		}
+		tr := makeplans.TimeRange{Open: makeplans.Clock(m)}
+		for m < minutesPerDay && c.open[m] && c.booked[m] < c.capacity {
+			m++
+		}
+		tr.Close = makeplans.Clock(m)
+		ranges = append(ranges, tr)
+	}
+	return ranges
+}
+
+// grid renders agenda columns side by side, one row per step minutes
+type grid struct {
+	step  int
+	width int
+	color bool
+}
+
+func (g grid) print(w io.Writer, cols []*agendaColumn, title func(*agendaColumn) string) {
+	first, last := minutesPerDay, 0
+	for _, c := range cols {
+		for m := 0; m < minutesPerDay; m++ {
+			if c.open[m] || c.booked[m] > 0 {
+				if m < first {
+					first = m
+				}
+				if m >= last {
+					last = m + 1
+				}
+			}
+		}
+	}
+	if first >= last {
+		fmt.Fprintln(w, "  closed, no bookings")
+		return
+	}
+	first -= first % g.step
+	if last%g.step > 0 {
+		last += g.step - last%g.step
+	}
+
+	line := "      "
+	for _, c := range cols {
+		line += " " + g.pad(title(c))
+	}
+	fmt.Fprintln(w, strings.TrimRight(line, " "))
+	for row := first; row < last; row += g.step {
+		line = makeplans.Clock(row).String() + " "
+		for _, c := range cols {
+			line += " " + g.cell(c, row, row+g.step)
+		}
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+
+	for _, c := range cols {
+		var free []string
+		for _, tr := range c.free() {
+			free = append(free, tr.String())
+		}
+		if len(free) == 0 {
+			free = []string{"none"}
+		}
+		fmt.Fprintf(w, "free %s: %s\n", title(c), strings.Join(free, ", "))
+	}
+}
+
+// cell shows the bookings starting in the row by name, "|" while they
+// continue, "." for free time and blanks when closed
+func (g grid) cell(c *agendaColumn, from, to int) string {
+	var starting []agendaBooking
+	var current *agendaBooking
+	for i, b := range c.bookings {
+		if b.from < to && b.to > from {
+			if current == nil {
+				current = &c.bookings[i]
+			}
+			if b.from >= from {
+				starting = append(starting, b)
+			}
+		}
+	}
+	switch {
+	case len(starting) == 1:
+		return g.paint(g.pad(bookingName(starting[0].Booking)), stateColors[starting[0].State])
+	case len(starting) > 1:
+		return g.paint(g.pad(fmt.Sprintf("%d bookings", len(starting))), stateColors[starting[0].State])
+	case current != nil:
+		return g.paint(g.pad("|"), stateColors[current.State])
+	}
+	for m := from; m < to && m < minutesPerDay; m++ {
+		if c.open[m] {
+			return g.paint(g.pad("."), colorDim)
+		}
+	}
+	return g.pad("")
+}
+
+func bookingName(b makeplans.Booking) string {
+	switch {
+	case b.Person != nil && len(b.Person.Name) > 0:
+		return b.Person.Name
+	case b.PersonID > 0:
+		return fmt.Sprintf("person %d", b.PersonID)
+	}
+	return fmt.Sprintf("booking %d", b.ID)
+}
+
+// pad truncates or pads s to the column width
+func (g grid) pad(s string) string {
+	if n := utf8.RuneCountInString(s); n > g.width {
+		r := []rune(s)
+		return string(r[:g.width-1]) + "~"
+	} else if n < g.width {
+		return s + strings.Repeat(" ", g.width-n)
+	}
+	return s
+}
+
+func (g grid) paint(s string, color string) string {
+	if !g.color || len(color) == 0 {
+		return s
+	}
+	return color + s + colorReset
+}
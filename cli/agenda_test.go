@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drewwells/makeplans"
+)
+
+func TestAgenda(t *testing.T) {
+	srv, a, out := testApp(t)
+	defer srv.Close()
+	a.now = func() time.Time {
+		return time.Date(2015, 12, 13, 18, 0, 0, 0, time.UTC)
+	}
+	jill := srv.AddResource(makeplans.Resource{Title: "jill",
+		OpeningHoursMon: []string{"12:00", "14:00"}})
+	bob := srv.AddResource(makeplans.Resource{Title: "bob",
+		OpeningHoursMon: []string{"09:00", "10:00", "12:00", "13:00"},
+		OpeningHoursTue: []string{"09:00", "10:00"}})
+	jane := srv.AddPerson(makeplans.Person{Name: "Jane Doe"})
+	at := func(day, h, m int) *time.Time {
+		t := time.Date(2015, 12, day, h, m, 0, 0, time.UTC)
+		return &t
+	}
+	srv.AddBooking(makeplans.Booking{ResourceID: jill.ID, PersonID: jane.ID,
+		BookedFrom: at(14, 12, 30), BookedTo: at(14, 13, 30),
+		State: makeplans.BookingConfirmed})
+	srv.AddBooking(makeplans.Booking{ID: 40, ResourceID: bob.ID,
+		BookedFrom: at(14, 9, 0), BookedTo: at(14, 9, 30),
+		State: makeplans.BookingAwaitingConfirmation})
+	srv.AddBooking(makeplans.Booking{ResourceID: bob.ID,
+		BookedFrom: at(14, 12, 0), BookedTo: at(14, 13, 0),
+		State: makeplans.BookingCancelled})
+	srv.AddBooking(makeplans.Booking{ResourceID: bob.ID, PersonID: jane.ID,
+		BookedFrom: at(15, 9, 0), BookedTo: at(15, 10, 0),
+		State: makeplans.BookingConfirmed})
+
+	if err := a.run([]string{"agenda", "-date", "tomorrow", "-width", "10", "-color", "never"}); err != nil {
+		t.Fatal(err)
+	}
+	e := `Monday 2015-12-14
+       jill       bob
+09:00             booking 40
+09:30             .
+10:00
+10:30
+11:00
+11:30
+12:00  .          .
+12:30  Jane Doe   .
+13:00  |
+13:30  .
+free jill: 12:00-12:30, 13:30-14:00
+free bob: 09:30-10:00, 12:00-13:00
+`
+	if out.String() != e {
+		t.Errorf("got:\n%s\nwanted:\n%s", out, e)
+	}
+
+	out.Reset()
+	err := a.run([]string{"agenda", "-week", "-date", "2015-12-15", "-resource", "2", "-step", "1h",
+		"-width", "8", "-color", "always"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if e := "bob, week of 2015-12-14"; lines[0] != e {
+		t.Errorf("got: %s wanted: %s", lines[0], e)
+	}
+	if e := "Mon 14   Tue 15   Wed 16"; !strings.Contains(lines[1], e) {
+		t.Errorf("got: %s wanted: %s", lines[1], e)
+	}
+	if e := "\x1b[32mJane Doe\x1b[0m"; !strings.Contains(lines[2], e) {
+		t.Errorf("got: %q wanted: %q", lines[2], e)
+	}
+	if e := "free Tue 15: none"; !strings.Contains(out.String(), e) {
+		t.Errorf("got: %s wanted: %s", out, e)
+	}
+}
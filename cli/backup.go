@@ -0,0 +1,150 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/drewwells/makeplans"
+)
+
+// backup writes a snapshot of the account to a file or stdout, gzipped
+// when the file ends in .gz
+func (a *app) backup(args []string) error {
+	fs := a.flags("backup")
+	file := fs.String("f", "-", "file to write the snapshot to")
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 0 {
+		return fmt.Errorf("unexpected arguments %v", pos)
+	}
+
+	s, err := makeplans.Backup(a.api)
+	if err != nil {
+		return err
+	}
+	if c, ok := a.api.(*makeplans.Client); ok {
+		s.Account = c.AccountName
+	}
+	if err := writeSnapshot(*file, a.out, s); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.err, "backed up %s\n", s.Counts())
+	if len(s.SkippedServices) > 0 {
+		fmt.Fprintf(a.err, "skipped inactive services %v, Makeplans doesn't list them\n",
+			s.SkippedServices)
+	}
+	return nil
+}
+
+func writeSnapshot(path string, stdout io.Writer, s *makeplans.Snapshot) error {
+	if path == "-" {
+		return makeplans.WriteSnapshot(stdout, s)
+	}
+	// Write next to the file and rename, keeping an older snapshot intact
+	// when the backup fails. Snapshots hold personal data, only the owner
+	// may read them.
+	tmp, err := os.OpenFile(path+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	var w io.Writer = tmp
+	var zw *gzip.Writer
+	if strings.HasSuffix(path, ".gz") {
+		zw = gzip.NewWriter(tmp)
+		w = zw
+	}
+	err = makeplans.WriteSnapshot(w, s)
+	if zw != nil && err == nil {
+		err = zw.Close()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func readSnapshot(path string, stdin io.Reader) (*makeplans.Snapshot, error) {
+	r := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	}
+	return makeplans.ReadSnapshot(r)
+}
+
+// restore recreates a snapshot in the account of the profile. The ID map
+// file keeps track of what was restored, running restore again with it
+// retries only the failures.
+func (a *app) restore(args []string) error {
+	fs := a.flags("restore")
+	idsPath := fs.String("ids", "", "read and update the snapshot to account ID map in this file")
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("expected one snapshot file, got %d arguments", len(pos))
+	}
+	s, err := readSnapshot(pos[0], a.in)
+	if err != nil {
+		return err
+	}
+	ids := makeplans.NewIDMap()
+	if len(*idsPath) > 0 {
+		bs, err := ioutil.ReadFile(*idsPath)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return err
+		default:
+			if err := json.Unmarshal(bs, &ids); err != nil {
+				return fmt.Errorf("%s: %s", *idsPath, err)
+			}
+		}
+	}
+
+	rep := makeplans.Restore(a.api, s, ids)
+	if len(*idsPath) > 0 {
+		bs, err := json.MarshalIndent(rep.IDs, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(*idsPath, bs, 0600); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(a.err, "restored %s\n", rep.Created)
+	if len(s.Events)+len(s.Bookings) > 0 {
+		fmt.Fprintf(a.err, "%d events and %d bookings are not restored\n", len(s.Events), len(s.Bookings))
+	}
+	if len(rep.Failures) == 0 {
+		return nil
+	}
+	if err := a.print(rep.Failures); err != nil {
+		return err
+	}
+	return fmt.Errorf("%d records could not be restored", len(rep.Failures))
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drewwells/makeplans"
+)
+
+func TestCLI_backup(t *testing.T) {
+	srv, a, _ := testApp(t)
+	defer srv.Close()
+
+	if err := a.run([]string{"people", "create", "-d", `{"name":"Jane"}`}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "backup.jsonl.gz")
+	if err := a.run([]string{"backup", "-f", path}); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := os.FileMode(0600); fi.Mode().Perm() != e {
+		t.Errorf("got: %s wanted: %s", fi.Mode().Perm(), e)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := makeplans.ReadSnapshot(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.People) != 1 || s.People[0].Name != "Jane" {
+		t.Errorf("got: %+v", s.People)
+	}
+	if e := "test"; s.Account != e {
+		t.Errorf("got: %s wanted: %s", s.Account, e)
+	}
+}
+
+func TestCLI_restore(t *testing.T) {
+	src, a, _ := testApp(t)
+	defer src.Close()
+	for _, args := range [][]string{
+		{"services", "create", "-d", `{"title":"Yoga","interval":60,"active":true}`},
+		{"resources", "create", "-d", `{"title":"Studio","opening_hours_mon":["08:00","16:00"]}`},
+		{"people", "create", "-d", `{"name":"Jane"}`},
+	} {
+		if err := a.run(args); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "backup.jsonl")
+	if err := a.run([]string{"backup", "-f", path}); err != nil {
+		t.Fatal(err)
+	}
+
+	dst, b, _ := testApp(t)
+	defer dst.Close()
+	ids := filepath.Join(dir, "ids.json")
+	if err := b.run([]string{"restore", path, "-ids", ids}); err != nil {
+		t.Fatal(err)
+	}
+	people, err := dst.Client().People()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(people) != 1 || people[0].Name != "Jane" {
+		t.Errorf("got: %+v", people)
+	}
+
+	// The ID map makes a second run a no-op
+	if err := b.run([]string{"restore", path, "-ids", ids}); err != nil {
+		t.Fatal(err)
+	}
+	resources, err := dst.Client().Resources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 1; len(resources) != e {
+		t.Errorf("got: %d wanted: %d", len(resources), e)
+	}
+	if e := []string{"08:00", "16:00"}; len(resources) > 0 && len(resources[0].OpeningHoursMon) != len(e) {
+		t.Errorf("got: %v wanted: %v", resources[0].OpeningHoursMon, e)
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drewwells/makeplans"
+)
+
+// book walks front desk staff through making a booking: service, date,
+// slot, person and count. Flags skip the matching prompts.
+func (a *app) book(args []string) error {
+	fs := a.flags("book")
+	serviceID := fs.Int("service", 0, "service id")
+	var date time.Time
+	a.timeVar(fs, &date, "date", "day of the booking")
+	personID := fs.Int("person", 0, "person id")
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 0 {
+		return fmt.Errorf("unexpected arguments %v", pos)
+	}
+	ask := a.prompter()
+
+	svc, err := a.pickService(ask, *serviceID)
+	if err != nil {
+		return err
+	}
+	slot, err := a.pickSlot(ask, svc, date)
+	if err != nil {
+		return err
+	}
+	person, err := a.pickPerson(ask, *personID)
+	if err != nil {
+		return err
+	}
+
+	b := makeplans.Booking{
+		ServiceID:  svc.ID,
+		BookedFrom: slot.Timestamp,
+		BookedTo:   slot.TimestampEnd,
+		PersonID:   person.ID,
+		Count:      1,
+	}
+	if slot.Free > 1 {
+		for {
+			s, err := ask.line(fmt.Sprintf("Count (%d free)", slot.Free), "1")
+			if err != nil {
+				return err
+			}
+			if n, err := strconv.Atoi(s); err == nil && n >= 1 && n <= slot.Free {
+				b.Count = n
+				break
+			}
+			fmt.Fprintf(ask.out, "enter a number from 1 to %d\n", slot.Free)
+		}
+	}
+	if b.Notes, err = ask.line("Notes", ""); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(ask.out, "\n%s, %s for %s", svc.Title, slotTime(slot), person.Name)
+	if b.Count > 1 {
+		fmt.Fprintf(ask.out, " (%d)", b.Count)
+	}
+	fmt.Fprintln(ask.out)
+	ok, err := ask.confirm("Book", true)
+	if err != nil || !ok {
+		return err
+	}
+	if person.ID == 0 {
+		if person, err = a.api.MakePerson(person); err != nil {
+			return err
+		}
+		b.PersonID = person.ID
+	}
+	b, err = a.api.MakeBooking(b)
+	if err != nil {
+		return err
+	}
+
+	// Move the booking along while Makeplans waits on the front desk
+	for _, step := range []struct {
+		from   makeplans.BookingState
+		action string
+		run    func(int) (makeplans.Booking, error)
+	}{
+		{makeplans.BookingUnverified, "Verify", a.api.BookingVerify},
+		{makeplans.BookingAwaitingConfirmation, "Confirm", a.api.BookingConfirm},
+	} {
+		if b.State != step.from {
+			continue
+		}
+		ok, err := ask.confirm(fmt.Sprintf("Booking %d is %s. %s it", b.ID, b.State, step.action), true)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		if b, err = step.run(b.ID); err != nil {
+			return err
+		}
+	}
+	return a.print(b)
+}
+
+func (a *app) pickService(ask *prompter, id int) (makeplans.Service, error) {
+	svcs, err := a.api.Services()
+	if err != nil {
+		return makeplans.Service{}, err
+	}
+	if id > 0 {
+		for _, svc := range svcs {
+			if svc.ID == id {
+				return svc, nil
+			}
+		}
+		return makeplans.Service{}, fmt.Errorf("service %d: %s", id, makeplans.ErrNotFound)
+	}
+	if len(svcs) == 0 {
+		return makeplans.Service{}, fmt.Errorf("no active services")
+	}
+	for i, svc := range svcs {
+		fmt.Fprintf(ask.out, "%3d) %s\n", i+1, svc.Title)
+	}
+	i, err := ask.choose("Service", len(svcs), 0)
+	if err != nil {
+		return makeplans.Service{}, err
+	}
+	return svcs[i-1], nil
+}
+
+// pickSlot lists the free slots on a day, suggesting the next available
+// date when the day is full
+func (a *app) pickSlot(ask *prompter, svc makeplans.Service, date time.Time) (makeplans.Slot, error) {
+	def := a.today().Format("2006-01-02")
+	for {
+		if date.IsZero() {
+			s, err := ask.line("Date", def)
+			if err != nil {
+				return makeplans.Slot{}, err
+			}
+			if err := a.timeFlag(&date).Set(s); err != nil {
+				fmt.Fprintln(ask.out, err)
+				continue
+			}
+		}
+		params := makeplans.SlotParams{From: date, To: date, OnlyFree: true}
+		slots, err := a.api.ServiceSlot(svc.ID, params)
+		if err != nil {
+			return makeplans.Slot{}, err
+		}
+		var free []makeplans.Slot
+		for _, slot := range slots {
+			if slot.Free > 0 && slot.Timestamp != nil {
+				free = append(free, slot)
+			}
+		}
+		if len(free) > 0 {
+			for i, slot := range free {
+				fmt.Fprintf(ask.out, "%3d) %s  %d free\n", i+1, slotTime(slot), slot.Free)
+			}
+			i, err := ask.choose("Slot", len(free), 0)
+			if err != nil {
+				return makeplans.Slot{}, err
+			}
+			return free[i-1], nil
+		}
+
+		fmt.Fprintf(ask.out, "No free slots on %s.", date.Format("2006-01-02"))
+		next, err := a.api.NextAvailableDate(svc.ID, makeplans.SlotParams{From: date, OnlyFree: true})
+		if err == nil {
+			def = next.Format("2006-01-02")
+			fmt.Fprintf(ask.out, " The next available date is %s.", def)
+		}
+		fmt.Fprintln(ask.out)
+		date = time.Time{}
+	}
+}
+
+func slotTime(slot makeplans.Slot) string {
+	s := slot.Timestamp.Format("Mon 2006-01-02 15:04")
+	if slot.TimestampEnd != nil {
+		s += "-" + slot.TimestampEnd.Format("15:04")
+	}
+	return s
+}
+
+// pickPerson searches people by name, email or phone number, or asks for a
+// new person. New people have no ID, they are created once the booking is
+// confirmed.
+func (a *app) pickPerson(ask *prompter, id int) (makeplans.Person, error) {
+	people, err := a.api.People()
+	if err != nil {
+		return makeplans.Person{}, err
+	}
+	if id > 0 {
+		for _, p := range people {
+			if p.ID == id {
+				return p, nil
+			}
+		}
+		return makeplans.Person{}, fmt.Errorf("person %d: %s", id, makeplans.ErrNotFound)
+	}
+	for {
+		q, err := ask.line("Person (name, email or phone, empty for new)", "")
+		if err != nil {
+			return makeplans.Person{}, err
+		}
+		if len(q) == 0 {
+			return a.newPerson(ask)
+		}
+		var found []makeplans.Person
+		for _, p := range people {
+			if matchPerson(p, q) {
+				found = append(found, p)
+			}
+		}
+		if len(found) == 0 {
+			fmt.Fprintf(ask.out, "No one matches %q\n", q)
+			continue
+		}
+		for i, p := range found {
+			fmt.Fprintf(ask.out, "%3d) %s  %s  %s\n", i+1, p.Name, p.Email, p.PhoneNumber)
+		}
+		fmt.Fprintf(ask.out, "%3d) New person\n", len(found)+1)
+		i, err := ask.choose("Person", len(found)+1, 1)
+		if err != nil {
+			return makeplans.Person{}, err
+		}
+		if i > len(found) {
+			return a.newPerson(ask)
+		}
+		return found[i-1], nil
+	}
+}
+
+func matchPerson(p makeplans.Person, q string) bool {
+	q = strings.ToLower(q)
+	for _, s := range []string{p.Name, p.Email, p.PhoneNumber} {
+		if strings.Contains(strings.ToLower(s), q) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *app) newPerson(ask *prompter) (makeplans.Person, error) {
+	var p makeplans.Person
+	var err error
+	if p.Name, err = ask.required("Name"); err != nil {
+		return p, err
+	}
+	if p.Email, err = ask.line("Email", ""); err != nil {
+		return p, err
+	}
+	p.PhoneNumber, err = ask.line("Phone", "")
+	return p, err
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drewwells/makeplans"
+	"github.com/drewwells/makeplans/makeplansmock"
+)
+
+func TestBook_server(t *testing.T) {
+	srv, a, out := testApp(t)
+	defer srv.Close()
+	srv.Now = func() time.Time {
+		return time.Date(2015, 12, 10, 9, 0, 0, 0, time.UTC)
+	}
+	srv.AddService(makeplans.Service{Title: "Massage", Active: true, Interval: 30})
+	svc := srv.AddService(makeplans.Service{Title: "Kickboxing", Active: true,
+		Interval: 60, BookingCapacity: 1})
+	res := srv.AddResource(makeplans.Resource{Title: "jill",
+		OpeningHoursMon: []string{"12:00", "14:00"}})
+	srv.AddProvider(makeplans.Provider{ResourceID: res.ID, ServiceID: svc.ID})
+	srv.AddPerson(makeplans.Person{Name: "Bob Smith", Email: "bob@example.com"})
+
+	stderr := &bytes.Buffer{}
+	a.err = stderr
+	a.in = strings.NewReader(strings.Join([]string{
+		"2",          // Kickboxing
+		"2015-12-14", // Monday
+		"2",          // 13:00
+		"jane",       // no match
+		"",           // new person
+		"Jane Doe",
+		"jane@example.com",
+		"",
+		"front door",
+		"",
+	}, "\n") + "\n")
+	if err := a.run([]string{"book"}); err != nil {
+		t.Log(stderr)
+		t.Fatal(err)
+	}
+
+	var b makeplans.Booking
+	if err := json.Unmarshal(out.Bytes(), &b); err != nil {
+		t.Fatal(err)
+	}
+	if e := svc.ID; b.ServiceID != e {
+		t.Errorf("got: %d wanted: %d", b.ServiceID, e)
+	}
+	if e := time.Date(2015, 12, 14, 13, 0, 0, 0, time.UTC); b.BookedFrom == nil || !b.BookedFrom.Equal(e) {
+		t.Errorf("got: %v wanted: %s", b.BookedFrom, e)
+	}
+	if e := "front door"; b.Notes != e {
+		t.Errorf("got: %s wanted: %s", b.Notes, e)
+	}
+	people, err := a.api.People()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 2; len(people) != e {
+		t.Fatalf("got: %d wanted: %d", len(people), e)
+	}
+	if b.PersonID != people[1].ID || people[1].Name != "Jane Doe" {
+		t.Errorf("booked person %d, created %+v", b.PersonID, people[1])
+	}
+	if e := `No one matches "jane"`; !strings.Contains(stderr.String(), e) {
+		t.Errorf("got: %s wanted: %s", stderr, e)
+	}
+}
+
+func TestBook_declined(t *testing.T) {
+	srv, a, out := testApp(t)
+	defer srv.Close()
+	srv.Now = func() time.Time {
+		return time.Date(2015, 12, 10, 9, 0, 0, 0, time.UTC)
+	}
+	svc := srv.AddService(makeplans.Service{Title: "Kickboxing", Active: true,
+		Interval: 60, BookingCapacity: 1})
+	res := srv.AddResource(makeplans.Resource{Title: "jill",
+		OpeningHoursMon: []string{"12:00", "14:00"}})
+	srv.AddProvider(makeplans.Provider{ResourceID: res.ID, ServiceID: svc.ID})
+
+	a.in = strings.NewReader(strings.Join([]string{
+		"2015-12-14",
+		"1",
+		"", // new person
+		"Jane Doe",
+		"",
+		"",
+		"",
+		"n",
+	}, "\n") + "\n")
+	if err := a.run([]string{"book", "-service", strconv.Itoa(svc.ID)}); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() > 0 {
+		t.Errorf("got: %s wanted no booking", out)
+	}
+	// Jane is only created with the booking
+	people, err := a.api.People()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 0; len(people) != e {
+		t.Errorf("got: %d wanted: %d", len(people), e)
+	}
+}
+
+func TestBook_transitions(t *testing.T) {
+	day := time.Date(2015, 12, 14, 0, 0, 0, 0, time.UTC)
+	from := day.Add(9 * time.Hour)
+	to := from.Add(time.Hour)
+	m := &makeplansmock.Client{
+		ServicesFunc: func() ([]makeplans.Service, error) {
+			return []makeplans.Service{{ID: 393, Title: "Cross fit"}}, nil
+		},
+		ServiceSlotFunc: func(id int, params makeplans.SlotParams) ([]makeplans.Slot, error) {
+			if !params.From.Equal(day) {
+				return nil, nil
+			}
+			return []makeplans.Slot{{Timestamp: &from, TimestampEnd: &to, Free: 3}}, nil
+		},
+		NextAvailableDateFunc: func(id int, params makeplans.SlotParams) (time.Time, error) {
+			return day, nil
+		},
+		PeopleFunc: func() ([]makeplans.Person, error) {
+			return []makeplans.Person{{ID: 7, Name: "Bob Smith", PhoneNumber: "555-0100"}}, nil
+		},
+		MakeBookingFunc: func(b makeplans.Booking) (makeplans.Booking, error) {
+			b.ID = 12
+			b.State = makeplans.BookingUnverified
+			return b, nil
+		},
+		BookingVerifyFunc: func(id int) (makeplans.Booking, error) {
+			return makeplans.Booking{ID: id, State: makeplans.BookingAwaitingConfirmation}, nil
+		},
+		BookingConfirmFunc: func(id int) (makeplans.Booking, error) {
+			return makeplans.Booking{ID: id, State: makeplans.BookingConfirmed}, nil
+		},
+	}
+	out := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	a := &app{
+		api: m,
+		in: strings.NewReader(strings.Join([]string{
+			"",  // next available date
+			"1", // 09:00
+			"0100",
+			"", // Bob
+			"4",
+			"2",
+			"",
+			"y",
+			"",
+			"",
+		}, "\n") + "\n"),
+		out: out,
+		err: stderr,
+		loc: time.UTC,
+	}
+	a.output.Set("json")
+	err := a.run([]string{"book", "-service", "393", "-date", "2015-12-11"})
+	if err != nil {
+		t.Log(stderr)
+		t.Fatal(err)
+	}
+
+	if e := "The next available date is 2015-12-14"; !strings.Contains(stderr.String(), e) {
+		t.Errorf("got: %s wanted: %s", stderr, e)
+	}
+	if e := "enter a number from 1 to 3"; !strings.Contains(stderr.String(), e) {
+		t.Errorf("got: %s wanted: %s", stderr, e)
+	}
+	calls := m.CallsTo("MakeBooking")
+	if e := 1; len(calls) != e {
+		t.Fatalf("got: %d wanted: %d", len(calls), e)
+	}
+	b := calls[0].Args[0].(makeplans.Booking)
+	if b.PersonID != 7 || b.Count != 2 || !b.BookedFrom.Equal(from) {
+		t.Errorf("got: %+v wanted person 7, count 2 at %s", b, from)
+	}
+	if e := 1; len(m.CallsTo("BookingConfirm")) != e {
+		t.Errorf("got: %d wanted: %d confirm", len(m.CallsTo("BookingConfirm")), e)
+	}
+	if !strings.Contains(out.String(), `"state": "confirmed"`) {
+		t.Errorf("got: %s wanted confirmed booking", out)
+	}
+
+	// Running out of input stops the flow
+	a.in = strings.NewReader("")
+	if err := a.run([]string{"book"}); err != errInputClosed {
+		t.Errorf("got: %v wanted: %v", err, errInputClosed)
+	}
+}
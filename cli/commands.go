@@ -0,0 +1,460 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/drewwells/makeplans"
+)
+
+// crud describes how the standard verbs map onto Client methods. Unset
+// funcs leave the verb out. get falls back to searching list.
+type crud[T any] struct {
+	id     func(T) int
+	list   func() ([]T, error)
+	get    func(id int) (T, error)
+	create func(T) (T, error)
+	update func(T) (T, error)
+	delete func(id int) (T, error)
+}
+
+func crudVerbs[T any](a *app, name string, c crud[T]) map[string]verb {
+	verbs := map[string]verb{}
+	get := c.get
+	if get == nil && c.list != nil {
+		get = func(id int) (T, error) {
+			var zero T
+			all, err := c.list()
+			if err != nil {
+				return zero, err
+			}
+			for _, v := range all {
+				if c.id(v) == id {
+					return v, nil
+				}
+			}
+			return zero, fmt.Errorf("%s %d: %s", name, id, makeplans.ErrNotFound)
+		}
+	}
+	if c.list != nil {
+		verbs["list"] = verb{run: func(args []string) error {
+			if _, err := parse(a.flags("list"), args); err != nil {
+				return err
+			}
+			all, err := c.list()
+			if err != nil {
+				return err
+			}
+			return a.print(all)
+		}}
+	}
+	if get != nil {
+		verbs["get"] = idVerb(a, "get", get)
+	}
+	if c.delete != nil {
+		verbs["delete"] = idVerb(a, "delete", c.delete)
+	}
+	if c.create != nil {
+		verbs["create"] = verb{
+			args: "[-d json | -f file]",
+			run: func(args []string) error {
+				fs := a.flags("create")
+				data := fs.String("d", "", "JSON object")
+				file := fs.String("f", "-", "JSON file, - for stdin")
+				if _, err := parse(fs, args); err != nil {
+					return err
+				}
+				var v T
+				if err := a.input(*data, *file, &v); err != nil {
+					return err
+				}
+				ret, err := c.create(v)
+				if err != nil {
+					return err
+				}
+				return a.print(ret)
+			},
+		}
+	}
+	if c.update != nil && get != nil {
+		verbs["update"] = verb{
+			args: "<id> [-d json | -f file]",
+			run: func(args []string) error {
+				fs := a.flags("update")
+				data := fs.String("d", "", "JSON object with the fields to change")
+				file := fs.String("f", "-", "JSON file, - for stdin")
+				pos, err := parse(fs, args)
+				if err != nil {
+					return err
+				}
+				id, err := oneID(pos)
+				if err != nil {
+					return err
+				}
+				v, err := get(id)
+				if err != nil {
+					return err
+				}
+				// Fields missing from the input keep their current value
+				if err := a.input(*data, *file, &v); err != nil {
+					return err
+				}
+				if c.id(v) != id {
+					return fmt.Errorf("input changes id to %d", c.id(v))
+				}
+				ret, err := c.update(v)
+				if err != nil {
+					return err
+				}
+				return a.print(ret)
+			},
+		}
+	}
+	return verbs
+}
+
+// idVerb calls fn with the single id argument and prints the result
+func idVerb[T any](a *app, name string, fn func(int) (T, error)) verb {
+	return verb{
+		args: "<id>",
+		run: func(args []string) error {
+			pos, err := parse(a.flags(name), args)
+			if err != nil {
+				return err
+			}
+			id, err := oneID(pos)
+			if err != nil {
+				return err
+			}
+			ret, err := fn(id)
+			if err != nil {
+				return err
+			}
+			return a.print(ret)
+		},
+	}
+}
+
+func commands(a *app) []command {
+	return []command{
+		a.services(),
+		a.bookings(),
+		a.people(),
+		a.resources(),
+		a.providers(),
+		a.events(),
+		a.slots(),
+		a.imports(),
+		a.export(),
+		{
+			name: "agenda",
+			args: "[-date 2006-01-02] [-week] [-resource id] [-step 30m] [-color auto|always|never]",
+			run:  a.agenda,
+		},
+		{
+			name: "book",
+			args: "[-service id] [-date 2006-01-02] [-person id]",
+			run:  a.book,
+		},
+		{
+			name: "plan",
+			args: "[-f makeplans.yaml] [-state file]",
+			run:  a.plan,
+		},
+		{
+			name: "apply",
+			args: "[-f makeplans.yaml] [-state file] [-yes]",
+			run:  a.apply,
+		},
+		{
+			name: "backup",
+			args: "[-f file.jsonl[.gz]]",
+			run:  a.backup,
+		},
+		{
+			name: "restore",
+			args: "<snapshot> [-ids file]",
+			run:  a.restore,
+		},
+		{
+			name:    "login",
+			offline: true,
+			args:    "[-profile name] [-account name] [-environment test|production] [-url url] [-default]",
+			run:     a.login,
+		},
+	}
+}
+
+func (a *app) services() command {
+	return command{
+		name: "services",
+		verbs: crudVerbs(a, "service", crud[makeplans.Service]{
+			id:     func(s makeplans.Service) int { return s.ID },
+			list:   func() ([]makeplans.Service, error) { return a.api.Services() },
+			create: func(s makeplans.Service) (makeplans.Service, error) { return a.api.ServiceCreate(s) },
+			update: func(s makeplans.Service) (makeplans.Service, error) { return a.api.ServiceSave(s) },
+			delete: func(id int) (makeplans.Service, error) { return a.api.ServiceDelete(id) },
+		}),
+	}
+}
+
+func (a *app) bookings() command {
+	verbs := crudVerbs(a, "booking", crud[makeplans.Booking]{
+		id:     func(b makeplans.Booking) int { return b.ID },
+		get:    func(id int) (makeplans.Booking, error) { return a.api.Booking(id) },
+		create: func(b makeplans.Booking) (makeplans.Booking, error) { return a.api.MakeBooking(b) },
+		update: func(b makeplans.Booking) (makeplans.Booking, error) { return a.api.BookingUpdate(b) },
+		delete: func(id int) (makeplans.Booking, error) { return a.api.BookingDelete(id) },
+	})
+	verbs["list"] = verb{
+		args: "[-all] [-service id] [-resource id] [-person id] [-event id] [-start t] [-end t] ...",
+		run:  a.listBookings,
+	}
+	verbs["cancel"] = idVerb(a, "cancel", func(id int) (makeplans.Booking, error) {
+		return a.api.BookingCancel(id)
+	})
+	verbs["confirm"] = idVerb(a, "confirm", func(id int) (makeplans.Booking, error) {
+		return a.api.BookingConfirm(id)
+	})
+	verbs["decline"] = idVerb(a, "decline", func(id int) (makeplans.Booking, error) {
+		return a.api.BookingDecline(id)
+	})
+	verbs["verify"] = idVerb(a, "verify", func(id int) (makeplans.Booking, error) {
+		return a.api.BookingVerify(id)
+	})
+	return command{name: "bookings", verbs: verbs}
+}
+
+func (a *app) listBookings(args []string) error {
+	fs := a.flags("list")
+	all := fs.Bool("all", false, "include deleted and past bookings")
+	var params makeplans.BookingParams
+	fs.IntVar(&params.ServiceID, "service", 0, "service id")
+	fs.IntVar(&params.ResourceID, "resource", 0, "resource id")
+	fs.IntVar(&params.PersonID, "person", 0, "person id")
+	fs.IntVar(&params.EventID, "event", 0, "event id")
+	fs.StringVar(&params.ExternalID, "external", "", "external id")
+	fs.StringVar(&params.CollectionID, "collection", "", "collection id")
+	a.timeVar(fs, &params.Start, "start", "bookings starting from")
+	a.timeVar(fs, &params.End, "end", "bookings ending before")
+	a.timeVar(fs, &params.Since, "since", "bookings changed since")
+	include := fs.String("include", "", "embed person,service,resource")
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 0 {
+		return fmt.Errorf("unexpected arguments %v", pos)
+	}
+	if len(*include) > 0 {
+		params.Include = strings.Split(*include, ",")
+	}
+	var bookings []makeplans.Booking
+	if *all {
+		// Makeplans doesn't filter all bookings
+		var filters []string
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name != "all" && f.Name != "output" && f.Name != "o" {
+				filters = append(filters, "-"+f.Name)
+			}
+		})
+		if len(filters) > 0 {
+			return fmt.Errorf("-all can't be combined with %s", strings.Join(filters, " "))
+		}
+		bookings, err = a.api.BookingAll()
+	} else {
+		bookings, err = a.api.Bookings(params)
+	}
+	if err != nil {
+		return err
+	}
+	return a.print(bookings)
+}
+
+func (a *app) people() command {
+	return command{
+		name: "people",
+		verbs: crudVerbs(a, "person", crud[makeplans.Person]{
+			id:     func(p makeplans.Person) int { return p.ID },
+			list:   func() ([]makeplans.Person, error) { return a.api.People() },
+			create: func(p makeplans.Person) (makeplans.Person, error) { return a.api.MakePerson(p) },
+			update: func(p makeplans.Person) (makeplans.Person, error) { return a.api.UpdatePerson(p) },
+			delete: func(id int) (makeplans.Person, error) {
+				p := makeplans.Person{ID: id}
+				return p, a.api.DeletePerson(p)
+			},
+		}),
+	}
+}
+
+func (a *app) resources() command {
+	verbs := crudVerbs(a, "resource", crud[makeplans.Resource]{
+		id:     func(r makeplans.Resource) int { return r.ID },
+		list:   func() ([]makeplans.Resource, error) { return a.api.Resources() },
+		get:    func(id int) (makeplans.Resource, error) { return a.api.Resource(id) },
+		create: func(r makeplans.Resource) (makeplans.Resource, error) { return a.api.MakeResource(r) },
+		update: func(r makeplans.Resource) (makeplans.Resource, error) { return a.api.ResourceUpdate(r) },
+		delete: func(id int) (makeplans.Resource, error) { return a.api.ResourceDelete(id) },
+	})
+	verbs["opening"] = verb{
+		args: "<id> -from t -to t",
+		run: func(args []string) error {
+			fs := a.flags("opening")
+			var from, to time.Time
+			a.timeVar(fs, &from, "from", "start of the period")
+			a.timeVar(fs, &to, "to", "end of the period")
+			pos, err := parse(fs, args)
+			if err != nil {
+				return err
+			}
+			id, err := oneID(pos)
+			if err != nil {
+				return err
+			}
+			r, err := a.api.ResourceOpening(id, from, to)
+			if err != nil {
+				return err
+			}
+			return a.print(r)
+		},
+	}
+	return command{name: "resources", verbs: verbs}
+}
+
+// providerChange is a provider created or deleted by sync
+type providerChange struct {
+	Change string `json:"change"`
+	makeplans.Provider
+}
+
+func (a *app) providers() command {
+	verbs := crudVerbs(a, "provider", crud[makeplans.Provider]{
+		id:     func(p makeplans.Provider) int { return p.ID },
+		list:   func() ([]makeplans.Provider, error) { return a.api.Providers() },
+		create: func(p makeplans.Provider) (makeplans.Provider, error) { return a.api.MakeProvider(p) },
+		update: func(p makeplans.Provider) (makeplans.Provider, error) { return a.api.ProviderUpdate(p) },
+		delete: func(id int) (makeplans.Provider, error) { return a.api.ProviderDelete(id) },
+	})
+	verbs["sync"] = verb{
+		args: "<resource id> <service id,...>",
+		run: func(args []string) error {
+			pos, err := parse(a.flags("sync"), args)
+			if err != nil {
+				return err
+			}
+			if len(pos) != 2 {
+				return fmt.Errorf("expected resource id and service ids, got %d arguments", len(pos))
+			}
+			resourceID, err := parseID(pos[0])
+			if err != nil {
+				return err
+			}
+			serviceIDs, err := parseIDs(pos[1])
+			if err != nil {
+				return err
+			}
+			created, deleted, err := a.api.SyncProviders(resourceID, serviceIDs)
+			if err != nil {
+				return err
+			}
+			var changes []providerChange
+			for _, p := range created {
+				changes = append(changes, providerChange{"created", p})
+			}
+			for _, p := range deleted {
+				changes = append(changes, providerChange{"deleted", p})
+			}
+			return a.print(changes)
+		},
+	}
+	return command{name: "providers", verbs: verbs}
+}
+
+func (a *app) events() command {
+	return command{
+		name: "events",
+		verbs: crudVerbs(a, "event", crud[makeplans.Event]{
+			id:   func(e makeplans.Event) int { return e.ID },
+			list: func() ([]makeplans.Event, error) { return a.api.Events() },
+		}),
+	}
+}
+
+// slotFlags registers the SlotParams flags shared by the slot verbs
+func (a *app) slotFlags(fs *flag.FlagSet, params *makeplans.SlotParams) *string {
+	a.timeVar(fs, &params.From, "from", "first day")
+	a.timeVar(fs, &params.To, "to", "last day")
+	fs.BoolVar(&params.OnlyFree, "free", false, "only slots with free capacity")
+	fs.IntVar(&params.MinimumFreeCount, "min", 0, "minimum free places, with -free")
+	fs.BoolVar(&params.DayBooking, "day", false, "one slot per day")
+	return fs.String("resources", "", "comma separated resource ids")
+}
+
+func (a *app) slots() command {
+	return command{
+		name: "slots",
+		verbs: map[string]verb{
+			"list": {
+				args: "(-service id | -resource id) [-from t] [-to t] [-free] ...",
+				run: func(args []string) error {
+					fs := a.flags("list")
+					var params makeplans.SlotParams
+					resources := a.slotFlags(fs, &params)
+					serviceID := fs.Int("service", 0, "service id")
+					resourceID := fs.Int("resource", 0, "resource id")
+					if err := slotArgs(fs, args, resources, &params); err != nil {
+						return err
+					}
+					var slots []makeplans.Slot
+					var err error
+					switch {
+					case *serviceID > 0 && *resourceID == 0:
+						slots, err = a.api.ServiceSlot(*serviceID, params)
+					case *resourceID > 0 && *serviceID == 0:
+						slots, err = a.api.ResourceSlots(*resourceID, params)
+					default:
+						return fmt.Errorf("pass one of -service or -resource")
+					}
+					if err != nil {
+						return err
+					}
+					return a.print(slots)
+				},
+			},
+			"next": {
+				args: "-service id [-from t] [-to t] [-free] ...",
+				run: func(args []string) error {
+					fs := a.flags("next")
+					var params makeplans.SlotParams
+					resources := a.slotFlags(fs, &params)
+					serviceID := fs.Int("service", 0, "service id")
+					if err := slotArgs(fs, args, resources, &params); err != nil {
+						return err
+					}
+					if *serviceID <= 0 {
+						return fmt.Errorf("pass -service")
+					}
+					slot, err := a.api.NextAvailableSlot(*serviceID, params)
+					if err != nil {
+						return err
+					}
+					return a.print(slot)
+				},
+			},
+		},
+	}
+}
+
+func slotArgs(fs *flag.FlagSet, args []string, resources *string, params *makeplans.SlotParams) error {
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 0 {
+		return fmt.Errorf("unexpected arguments %v", pos)
+	}
+	params.SelectedResources, err = parseIDs(*resources)
+	return err
+}
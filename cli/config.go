@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/drewwells/makeplans"
+)
+
+// Environment variables overriding the selected profile
+const (
+	envConfig  = "MAKEPLANS_CONFIG"
+	envProfile = "MAKEPLANS_PROFILE"
+	envAccount = "MAKEPLANS_ACCOUNT"
+	envToken   = "MAKEPLANS_TOKEN"
+	envURL     = "MAKEPLANS_URL"
+)
+
+// environments are the Makeplans installations a profile can point at
+var environments = map[string]string{
+	"test":       makeplans.DefaultURL,
+	"production": "https://%s.makeplans.net/api/v1",
+}
+
+// profile is a named set of credentials
+type profile struct {
+	Account string `json:"account"`
+	Token   string `json:"token"`
+	// Environment is test or production, defaults to test
+	Environment string `json:"environment,omitempty"`
+	// URL overrides Environment. %s is replaced by the account name.
+	URL string `json:"url,omitempty"`
+	// Timezone of the account, ie. Europe/Oslo, dates are read and shown
+	// in it. Defaults to the local zone.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// config is the CLI config file, it holds the token so it is written
+// readable by the owner only
+type config struct {
+	Default  string             `json:"default,omitempty"`
+	Profiles map[string]profile `json:"profiles"`
+}
+
+// configPath returns -config, $MAKEPLANS_CONFIG or the user config dir
+func (a *app) configPath() (string, error) {
+	if len(a.config) > 0 {
+		return a.config, nil
+	}
+	if p := a.getenv(envConfig); len(p) > 0 {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "makeplans", "config.json"), nil
+}
+
+// loadConfig reads the config file, a missing file is an empty config
+func (a *app) loadConfig() (config, error) {
+	cfg := config{Profiles: map[string]profile{}}
+	path, err := a.configPath()
+	if err != nil {
+		return cfg, err
+	}
+	bs, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(bs, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %s", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]profile{}
+	}
+	return cfg, nil
+}
+
+// saveConfig writes the config with 0600 permissions
+func (a *app) saveConfig(cfg config) error {
+	path, err := a.configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	bs, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, bs, 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(tmp, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// profileName returns -profile, $MAKEPLANS_PROFILE, the config default or
+// "default"
+func (a *app) profileName(cfg config) string {
+	switch {
+	case len(a.profile) > 0:
+		return a.profile
+	case len(a.getenv(envProfile)) > 0:
+		return a.getenv(envProfile)
+	case len(cfg.Default) > 0:
+		return cfg.Default
+	}
+	return "default"
+}
+
+// credentials resolves the selected profile and applies the environment
+// overrides. The profile doesn't need to exist when the environment
+// provides account and token.
+func (a *app) credentials() (profile, error) {
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return profile{}, err
+	}
+	name := a.profileName(cfg)
+	p, ok := cfg.Profiles[name]
+	if v := a.getenv(envAccount); len(v) > 0 {
+		p.Account = v
+	}
+	if v := a.getenv(envToken); len(v) > 0 {
+		p.Token = v
+	}
+	if v := a.getenv(envURL); len(v) > 0 {
+		p.URL = v
+	}
+	if len(p.Account) == 0 || len(p.Token) == 0 {
+		if !ok {
+			return p, fmt.Errorf("profile %q not found, run makeplans login or set %s and %s",
+				name, envAccount, envToken)
+		}
+		return p, fmt.Errorf("profile %q is missing account or token", name)
+	}
+	return p, nil
+}
+
+// client builds the API client for a profile
+func (p profile) client() (*makeplans.Client, error) {
+	c := makeplans.New(p.Account, p.Token)
+	switch {
+	case len(p.URL) > 0:
+		c.URL = p.URL
+	case len(p.Environment) > 0:
+		u, ok := environments[p.Environment]
+		if !ok {
+			return nil, fmt.Errorf("unknown environment %q, use one of %s",
+				p.Environment, strings.Join(environmentNames(), ", "))
+		}
+		c.URL = u
+	}
+	if !strings.Contains(c.URL, "%s") {
+		// A plain URL doesn't include the account
+		c.Resolver = func(urlTmpl string, accountName string) string {
+			return urlTmpl
+		}
+	}
+	return c, nil
+}
+
+func environmentNames() []string {
+	var names []string
+	for name := range environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// connect creates the API client from the selected profile
+func (a *app) connect() error {
+	p, err := a.credentials()
+	if err != nil {
+		return err
+	}
+	c, err := p.client()
+	if err != nil {
+		return err
+	}
+	if len(p.Timezone) > 0 {
+		loc, err := time.LoadLocation(p.Timezone)
+		if err != nil {
+			return err
+		}
+		c.Location, a.loc = loc, loc
+	}
+	a.api = c
+	return nil
+}
+
+// login checks the credentials against Makeplans and stores them in a
+// profile. Account and token default to MAKEPLANS_ACCOUNT and
+// MAKEPLANS_TOKEN, missing ones are prompted for.
+func (a *app) login(args []string) error {
+	fs := a.flags("login")
+	fs.StringVar(&a.profile, "profile", a.profile, "profile to store the credentials in")
+	var p profile
+	fs.StringVar(&p.Account, "account", "", "account name")
+	fs.StringVar(&p.Environment, "environment", "", "test or production")
+	fs.StringVar(&p.URL, "url", "", "API url, %s is replaced by the account name")
+	fs.StringVar(&p.Timezone, "timezone", "", "account time zone, ie. Europe/Oslo")
+	def := fs.Bool("default", false, "make this the default profile")
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 0 {
+		return fmt.Errorf("unexpected arguments %v", pos)
+	}
+
+	ask := a.prompter()
+	if len(p.Account) == 0 {
+		p.Account = a.getenv(envAccount)
+	}
+	if len(p.Account) == 0 {
+		if p.Account, err = ask.required("Account"); err != nil {
+			return err
+		}
+	}
+	if p.Token = a.getenv(envToken); len(p.Token) == 0 {
+		if p.Token, err = ask.secret("Token"); err != nil {
+			return err
+		}
+	}
+
+	c, err := p.client()
+	if err != nil {
+		return err
+	}
+	if _, err := c.Services(); err != nil {
+		return fmt.Errorf("login failed: %s", err)
+	}
+
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	name := a.profileName(cfg)
+	cfg.Profiles[name] = p
+	if *def || len(cfg.Profiles) == 1 {
+		cfg.Default = name
+	}
+	if err := a.saveConfig(cfg); err != nil {
+		return err
+	}
+	path, _ := a.configPath()
+	fmt.Fprintf(a.err, "Saved profile %q to %s\n", name, path)
+	return nil
+}
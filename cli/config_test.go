@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drewwells/makeplans"
+	"github.com/drewwells/makeplans/makeplanstest"
+)
+
+func configApp(t *testing.T, env map[string]string) (*app, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	a := &app{
+		in:     strings.NewReader(""),
+		out:    out,
+		err:    &bytes.Buffer{},
+		loc:    time.UTC,
+		config: filepath.Join(t.TempDir(), "makeplans", "config.json"),
+		getenv: func(key string) string { return env[key] },
+	}
+	a.output.Set("template={{.Title}}")
+	return a, out
+}
+
+func TestConfig_login(t *testing.T) {
+	srv := makeplanstest.NewServer()
+	defer srv.Close()
+	srv.AddService(makeplans.Service{Title: "Yoga", Active: true, Interval: 60})
+
+	a, out := configApp(t, nil)
+	a.in = strings.NewReader("acme\nsecret\n")
+	err := a.run([]string{"-profile", "staging", "login", "-url", srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(a.config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := os.FileMode(0600); fi.Mode().Perm() != e {
+		t.Errorf("got: %s wanted: %s", fi.Mode().Perm(), e)
+	}
+
+	cfg, err := a.loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := cfg.Profiles["staging"]
+	if p.Account != "acme" || p.Token != "secret" {
+		t.Errorf("got: %+v wanted acme/secret", p)
+	}
+	if e := "staging"; cfg.Default != e {
+		t.Errorf("got: %s wanted: %s", cfg.Default, e)
+	}
+
+	// The first profile is the default
+	b, out := configApp(t, nil)
+	b.config = a.config
+	if err := b.run([]string{"services", "list"}); err != nil {
+		t.Fatal(err)
+	}
+	if e := "Yoga\n"; out.String() != e {
+		t.Errorf("got: %q wanted: %q", out, e)
+	}
+
+	c, _ := configApp(t, nil)
+	c.config = a.config
+	err = c.run([]string{"-profile", "prod", "services", "list"})
+	if err == nil || !strings.Contains(err.Error(), `profile "prod" not found`) {
+		t.Errorf("got: %v wanted profile not found", err)
+	}
+}
+
+func TestConfig_loginEnv(t *testing.T) {
+	srv := makeplanstest.NewServer()
+	defer srv.Close()
+
+	a, _ := configApp(t, map[string]string{envAccount: "acme"})
+	a.in = strings.NewReader("secret\n")
+	if err := a.run([]string{"login", "-url", srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+	if prompts := a.err.(*bytes.Buffer).String(); strings.Contains(prompts, "Account") {
+		t.Errorf("got: %q wanted no account prompt", prompts)
+	}
+	cfg, err := a.loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := cfg.Profiles["default"]; p.Account != "acme" || p.Token != "secret" {
+		t.Errorf("got: %+v wanted acme/secret", p)
+	}
+}
+
+func TestConfig_env(t *testing.T) {
+	a, _ := configApp(t, map[string]string{
+		envAccount: "acme",
+		envToken:   "secret",
+	})
+	p, err := a.credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := p.client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "http://acme.test.makeplans.net/api/v1"; c.Resolver(c.URL, c.AccountName) != e {
+		t.Errorf("got: %s wanted: %s", c.Resolver(c.URL, c.AccountName), e)
+	}
+
+	cfg := config{Profiles: map[string]profile{
+		"prod": {Account: "acme", Token: "old", Environment: "production"},
+	}}
+	if err := a.saveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	a.profile = "prod"
+	p, err = a.credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "secret"; p.Token != e {
+		t.Errorf("got: %s wanted: %s", p.Token, e)
+	}
+	c, err = p.client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "https://acme.makeplans.net/api/v1"; c.Resolver(c.URL, c.AccountName) != e {
+		t.Errorf("got: %s wanted: %s", c.Resolver(c.URL, c.AccountName), e)
+	}
+
+	_, err = profile{Environment: "staging"}.client()
+	if err == nil {
+		t.Error("expected unknown environment error")
+	}
+}
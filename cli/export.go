@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/drewwells/makeplans"
+)
+
+func (a *app) export() command {
+	return command{
+		name: "export",
+		verbs: map[string]verb{
+			"ics": {
+				args: "[-service id] [-resource id] [-person id] [-start t] [-end t] [-events] [-f file.ics]",
+				run:  a.exportICS,
+			},
+		},
+	}
+}
+
+// exportICS writes bookings, and optionally events, as an iCalendar file
+// for calendar apps
+func (a *app) exportICS(args []string) error {
+	fs := a.flags("ics")
+	var params makeplans.BookingParams
+	fs.IntVar(&params.ServiceID, "service", 0, "service id")
+	fs.IntVar(&params.ResourceID, "resource", 0, "resource id")
+	fs.IntVar(&params.PersonID, "person", 0, "person id")
+	a.timeVar(fs, &params.Start, "start", "bookings starting from")
+	a.timeVar(fs, &params.End, "end", "bookings up to and including the day")
+	events := fs.Bool("events", false, "include events")
+	file := fs.String("f", "-", "file to write the calendar to")
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 0 {
+		return fmt.Errorf("unexpected arguments %v", pos)
+	}
+
+	cal := makeplans.Calendar{Location: a.zone()}
+	if c, ok := a.api.(*makeplans.Client); ok {
+		cal.Name = c.AccountName
+		cal.Domain = c.AccountName + ".makeplans.net"
+	}
+	// Makeplans only lists active bookings by filter, cancelled ones are
+	// needed to remove them from calendars
+	all, err := a.api.BookingAll()
+	if err != nil {
+		return err
+	}
+	people := false
+	for _, b := range all {
+		if params.ServiceID > 0 && b.ServiceID != params.ServiceID ||
+			params.ResourceID > 0 && b.ResourceID != params.ResourceID ||
+			params.PersonID > 0 && b.PersonID != params.PersonID ||
+			b.BookedFrom == nil || !inDays(*b.BookedFrom, params.Start, params.End) {
+			continue
+		}
+		cal.Bookings = append(cal.Bookings, b)
+		people = people || b.PersonID > 0
+	}
+	if people {
+		all, err := a.api.People()
+		if err != nil {
+			return err
+		}
+		byID := make(map[int]*makeplans.Person, len(all))
+		for i := range all {
+			byID[all[i].ID] = &all[i]
+		}
+		for i, b := range cal.Bookings {
+			cal.Bookings[i].Person = byID[b.PersonID]
+		}
+	}
+	if *events {
+		all, err := a.api.Events()
+		if err != nil {
+			return err
+		}
+		for _, e := range all {
+			if params.ServiceID > 0 && e.ServiceID != params.ServiceID ||
+				params.ResourceID > 0 && e.ResourceID != params.ResourceID ||
+				e.Start == nil || !inDays(*e.Start, params.Start, params.End) {
+				continue
+			}
+			cal.Events = append(cal.Events, e)
+		}
+	}
+	// Bookings and events only refer to their service and resource
+	if cal.Services, err = a.api.Services(); err != nil {
+		return err
+	}
+	if cal.Resources, err = a.api.Resources(); err != nil {
+		return err
+	}
+
+	if *file == "-" {
+		return makeplans.WriteICS(a.out, cal)
+	}
+	f, err := os.Create(*file)
+	if err != nil {
+		return err
+	}
+	err = makeplans.WriteICS(f, cal)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// zone is the time zone of exported calendars. Calendar apps need a zone
+// name, time.Local only has one when TZ names it, otherwise UTC is used.
+func (a *app) zone() *time.Location {
+	if a.loc.String() != "Local" {
+		return a.loc
+	}
+	if tz := os.Getenv("TZ"); len(tz) > 0 {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// inDays reports whether t is on the days from start to end, both included,
+// the way Makeplans filters bookings. Zero times leave the range open.
+func inDays(t time.Time, start time.Time, end time.Time) bool {
+	if !start.IsZero() {
+		y, m, d := start.Date()
+		if t.Before(time.Date(y, m, d, 0, 0, 0, 0, start.Location())) {
+			return false
+		}
+	}
+	if !end.IsZero() {
+		y, m, d := end.Date()
+		if !t.Before(time.Date(y, m, d+1, 0, 0, 0, 0, end.Location())) {
+			return false
+		}
+	}
+	return true
+}
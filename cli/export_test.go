@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drewwells/makeplans"
+)
+
+func TestCLI_exportICS(t *testing.T) {
+	srv, a, out := testApp(t)
+	defer srv.Close()
+	from := time.Date(2015, 12, 14, 9, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	svc := srv.AddService(makeplans.Service{Title: "Yoga", Active: true})
+	r := srv.AddResource(makeplans.Resource{Title: "Studio"})
+	p := srv.AddPerson(makeplans.Person{Name: "Jane", Email: "jane@example.com"})
+	b := srv.AddBooking(makeplans.Booking{
+		ServiceID: svc.ID, ResourceID: r.ID, PersonID: p.ID,
+		BookedFrom: &from, BookedTo: &to, State: makeplans.BookingConfirmed,
+	})
+	cancelled := srv.AddBooking(makeplans.Booking{
+		ServiceID: svc.ID, ResourceID: r.ID, PersonID: p.ID,
+		BookedFrom: &from, BookedTo: &to, State: makeplans.BookingCancelled,
+	})
+	srv.AddEvent(makeplans.Event{ServiceID: svc.ID, ResourceID: r.ID, Start: &from, End: &to})
+	// After -end
+	next := from.AddDate(0, 0, 1)
+	srv.AddBooking(makeplans.Booking{ServiceID: svc.ID, ResourceID: r.ID,
+		BookedFrom: &next, State: makeplans.BookingConfirmed})
+	srv.AddEvent(makeplans.Event{Title: "Tomorrow", ServiceID: svc.ID, Start: &next})
+
+	if err := a.run([]string{"export", "ics", "-events", "-start", "2015-12-14", "-end", "2015-12-14"}); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	if !strings.HasPrefix(got, "BEGIN:VCALENDAR\r\n") {
+		t.Errorf("got:\n%s", got)
+	}
+	for _, e := range []string{
+		"UID:booking-" + strconv.Itoa(b.ID) + "@test.makeplans.net\r\n",
+		"SUMMARY:Yoga - Jane\r\n",
+		"ATTENDEE;CN=Jane:mailto:jane@example.com\r\n",
+		"STATUS:CONFIRMED\r\n",
+		// Cancelled bookings stay to remove them from calendars
+		"UID:booking-" + strconv.Itoa(cancelled.ID) + "@test.makeplans.net\r\n",
+		"STATUS:CANCELLED\r\n",
+		// The event is named after its service
+		"DTEND:20151214T100000Z\r\nSUMMARY:Yoga\r\nLOCATION:Studio\r\n",
+	} {
+		if !strings.Contains(got, e) {
+			t.Errorf("got:\n%s\nwanted: %q", got, e)
+		}
+	}
+	if e := 3; strings.Count(got, "BEGIN:VEVENT") != e {
+		t.Errorf("got: %d wanted: %d", strings.Count(got, "BEGIN:VEVENT"), e)
+	}
+}
+
+func TestCLI_exportZone(t *testing.T) {
+	a := &app{loc: time.Local}
+	defer os.Setenv("TZ", os.Getenv("TZ"))
+	os.Setenv("TZ", "")
+	if e := "UTC"; a.zone().String() != e {
+		t.Errorf("got: %s wanted: %s", a.zone(), e)
+	}
+	os.Setenv("TZ", "Europe/Oslo")
+	if _, err := time.LoadLocation("Europe/Oslo"); err == nil {
+		if e := "Europe/Oslo"; a.zone().String() != e {
+			t.Errorf("got: %s wanted: %s", a.zone(), e)
+		}
+	}
+	a.loc = time.UTC
+	if e := "UTC"; a.zone().String() != e {
+		t.Errorf("got: %s wanted: %s", a.zone(), e)
+	}
+}
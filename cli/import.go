@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/drewwells/makeplans"
+)
+
+func (a *app) imports() command {
+	return command{
+		name: "import",
+		verbs: map[string]verb{
+			"people": {
+				args: "<file> [-map column=field,...] [-dry-run] [-concurrency n] [-report file]",
+				run: func(args []string) error {
+					return a.runImport(args, (*makeplans.Importer).ImportPeople)
+				},
+			},
+			"bookings": {
+				args: "<file> [-map column=field,...] [-dry-run] [-concurrency n] [-report file]",
+				run: func(args []string) error {
+					return a.runImport(args, (*makeplans.Importer).ImportBookings)
+				},
+			},
+		},
+	}
+}
+
+// mappingFlag reads column=field pairs, separated by commas or repeated
+type mappingFlag makeplans.ImportMapping
+
+func (m mappingFlag) String() string {
+	var pairs []string
+	for col, field := range m {
+		pairs = append(pairs, col+"="+field)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (m mappingFlag) Set(s string) error {
+	for _, pair := range strings.Split(s, ",") {
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 {
+			return fmt.Errorf("invalid mapping %q, use column=field", pair)
+		}
+		m[strings.TrimSpace(pair[:i])] = strings.TrimSpace(pair[i+1:])
+	}
+	return nil
+}
+
+// runImport reads the rows of a CSV or JSON file and imports them with fn.
+// The results are printed per row, failed rows make the command fail.
+func (a *app) runImport(args []string, fn func(*makeplans.Importer, []makeplans.ImportRow) ([]makeplans.ImportResult, error)) error {
+	fs := a.flags("import")
+	im := &makeplans.Importer{
+		API:      a.api,
+		Mapping:  makeplans.ImportMapping{},
+		Location: a.loc,
+	}
+	fs.Var(mappingFlag(im.Mapping), "map", "map file columns to fields, ie. E-mail=email")
+	fs.BoolVar(&im.DryRun, "dry-run", false, "check every row without changing anything")
+	fs.IntVar(&im.Concurrency, "concurrency", 4, "requests in flight")
+	format := fs.String("format", "", "csv or json, defaults to the file extension")
+	report := fs.String("report", "", "also write the results as CSV to this file")
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("expected one file, got %d arguments", len(pos))
+	}
+
+	rows, err := a.readRows(pos[0], *format)
+	if err != nil {
+		return err
+	}
+	results, err := fn(im, rows)
+	if err != nil {
+		return err
+	}
+	if len(*report) > 0 {
+		f, err := os.Create(*report)
+		if err != nil {
+			return err
+		}
+		err = makeplans.WriteImportReport(f, results)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := a.print(results); err != nil {
+		return err
+	}
+	summary := makeplans.ImportSummary(results)
+	if im.DryRun {
+		summary = "dry run: " + summary
+	}
+	fmt.Fprintln(a.err, summary)
+	for _, res := range results {
+		if res.Action == makeplans.ImportFailed {
+			return fmt.Errorf("import failed for some rows")
+		}
+	}
+	return nil
+}
+
+// readRows reads path, "-" for stdin, as csv or json
+func (a *app) readRows(path string, format string) ([]makeplans.ImportRow, error) {
+	if len(format) == 0 {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	var read func(io.Reader) ([]makeplans.ImportRow, error)
+	switch format {
+	case "csv":
+		read = makeplans.ReadImportCSV
+	case "json":
+		read = makeplans.ReadImportJSON
+	default:
+		return nil, fmt.Errorf("unknown format %q, use -format csv or json", format)
+	}
+	if path == "-" {
+		return read(a.in)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return read(f)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drewwells/makeplans"
+)
+
+func TestCLI_import(t *testing.T) {
+	srv, a, out := testApp(t)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	people := filepath.Join(dir, "people.csv")
+	err := ioutil.WriteFile(people, []byte("Name,E-mail\nJane,jane@example.com\n,bob@example.com\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := filepath.Join(dir, "report.csv")
+	err = a.run([]string{"import", "people", people, "-map", "Name=name,E-mail=email", "-report", report})
+	if err == nil {
+		t.Fatal("expected the failed row to fail the import")
+	}
+	var results []makeplans.ImportResult
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+	if e := 2; len(results) != e {
+		t.Fatalf("got: %d wanted: %d", len(results), e)
+	}
+	if results[0].Action != makeplans.ImportCreated || results[1].Action != makeplans.ImportFailed {
+		t.Errorf("got: %+v", results)
+	}
+	if e := "1 created, 1 failed\n"; a.err.(*bytes.Buffer).String() != e {
+		t.Errorf("got: %q wanted: %q", a.err.(*bytes.Buffer).String(), e)
+	}
+	bs, err := ioutil.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(bs), "line,action,id,error\n2,created,") {
+		t.Errorf("got: %q", bs)
+	}
+
+	out.Reset()
+	a.in = strings.NewReader(`[{"external_id":"b1","service_id":393,"person_email":"jane@example.com","booked_from":"2015-12-14 09:00"}]`)
+	if err := a.run([]string{"import", "bookings", "-", "-format", "json", "-dry-run"}); err != nil {
+		t.Fatal(err)
+	}
+	bookings, err := srv.Client().Bookings(makeplans.BookingParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 0; len(bookings) != e {
+		t.Errorf("dry run created bookings got: %d wanted: %d", len(bookings), e)
+	}
+
+	if err := a.run([]string{"import", "people", "people.txt"}); err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Errorf("got: %v", err)
+	}
+}
@@ -1,73 +1,299 @@
+// Command makeplans operates a Makeplans account from the terminal.
+//
+//	makeplans [-profile name] <command> <verb> [flags] [args]
+//
+// Commands are services, bookings, people, resources, providers, events
+// and slots. Most support the verbs list, get, create, update and delete.
+// create and update read a JSON object from -d, -f or stdin. update only
+// changes the fields passed.
+//
+// Credentials are kept in profiles, stored by makeplans login in the user
+// config dir. MAKEPLANS_ACCOUNT and MAKEPLANS_TOKEN override the profile.
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/drewwells/makeplans"
 )
 
-type account struct {
-	Name  string
-	Token string
+// errUsage is returned after usage has been printed
+var errUsage = errors.New("usage")
+
+// app holds the client and streams shared by every command
+type app struct {
+	api makeplans.API
+	in  io.Reader
+	out io.Writer
+	err io.Writer
+	// loc is the zone dates passed on the command line are read in
+	loc    *time.Location
+	now    func() time.Time
+	output output
+	// config and profile select the credentials, see credentials
+	config  string
+	profile string
+	getenv  func(string) string
 }
 
 func main() {
-	if len(os.Args) < 2 {
-		log.Fatal("must pass key file")
+	a := &app{
+		in:     os.Stdin,
+		out:    os.Stdout,
+		err:    os.Stderr,
+		loc:    time.Local,
+		now:    time.Now,
+		getenv: os.Getenv,
 	}
-	bs, err := ioutil.ReadFile(os.Args[1])
-	if err != nil {
-		log.Fatal(err)
+	if err := a.run(os.Args[1:]); err != nil {
+		if err != errUsage {
+			fmt.Fprintln(a.err, err)
+		}
+		os.Exit(1)
 	}
+}
 
-	ac := account{}
-	err = json.Unmarshal(bs, &ac)
-	if err != nil {
-		log.Fatal(err)
+// run parses the global flags and dispatches the remaining args, ie.
+// ["bookings", "get", "12"], to a verb. The client is created from the
+// profile unless one is set already.
+func (a *app) run(args []string) error {
+	cmds := commands(a)
+	fs := a.flags("makeplans")
+	fs.StringVar(&a.config, "config", a.config, "config file, defaults to $"+envConfig+
+		" or config.json in the user config dir")
+	fs.StringVar(&a.profile, "profile", a.profile, "profile in the config file, defaults to $"+envProfile)
+	fs.Usage = func() {
+		usage(a.err, cmds)
+		fmt.Fprintln(a.err, "\nflags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	args = fs.Args()
+	if len(args) == 0 {
+		fs.Usage()
+		return errUsage
 	}
+	for _, cmd := range cmds {
+		if cmd.name != args[0] {
+			continue
+		}
+		run := cmd.run
+		if run == nil {
+			if len(args) < 2 {
+				cmd.usage(a.err)
+				return errUsage
+			}
+			v, ok := cmd.verbs[args[1]]
+			if !ok {
+				fmt.Fprintf(a.err, "unknown verb %q\n", args[1])
+				cmd.usage(a.err)
+				return errUsage
+			}
+			run, args = v.run, args[1:]
+		}
+		if a.api == nil && !cmd.offline {
+			if err := a.connect(); err != nil {
+				return err
+			}
+		}
+		return run(args[1:])
+	}
+	fmt.Fprintf(a.err, "unknown command %q\n", args[0])
+	usage(a.err, cmds)
+	return errUsage
+}
 
-	client := makeplans.New(ac.Name, ac.Token)
+func usage(w io.Writer, cmds []command) {
+	fmt.Fprintln(w, "usage: makeplans [-profile name] [-output format] <command> <verb> [flags] [args]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, cmd := range cmds {
+		if cmd.run != nil {
+			fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.args)
+			continue
+		}
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, strings.Join(cmd.names(), ", "))
+	}
+}
 
-	svcs, err := client.Services()
-	if err != nil {
-		log.Fatal(err)
+// verb is a single action of a command
+type verb struct {
+	args string
+	run  func(args []string) error
+}
+
+// command is a noun, ie. bookings, and the verbs acting on it. Commands
+// without verbs, ie. book, set run instead.
+type command struct {
+	name  string
+	args  string
+	verbs map[string]verb
+	run   func(args []string) error
+	// offline commands run without a client
+	offline bool
+}
+
+func (cmd command) names() []string {
+	var names []string
+	for name := range cmd.verbs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (cmd command) usage(w io.Writer) {
+	fmt.Fprintf(w, "usage of %s:\n", cmd.name)
+	if cmd.run != nil {
+		fmt.Fprintf(w, "  makeplans %s %s\n", cmd.name, cmd.args)
 	}
-	for _, svc := range svcs {
-		fmt.Println(svc)
+	for _, name := range cmd.names() {
+		fmt.Fprintf(w, "  makeplans %s %s %s\n", cmd.name, name, cmd.verbs[name].args)
 	}
+}
 
-	svc := svcs[0]
-	svc.Price = "20.0"
-	err = client.ServiceSave(svc)
-	if err != nil {
-		log.Fatal(err)
+// flags returns a flag set reporting errors instead of exiting
+func (a *app) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.err)
+	a.outputVar(fs)
+	return fs
+}
+
+// parse allows flags after positional arguments, ie. update 12 -d '{}'
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return pos, nil
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
 	}
+}
 
-	// get next cross fit
-	slots, err := client.SlotNextDate("393")
-	if err != nil {
-		// log.Fatal(err)
+// oneID reads the single id argument of a verb
+func oneID(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("expected one id, got %d arguments", len(args))
 	}
-	for _, slot := range slots {
-		fmt.Println("Next running", slot)
+	return parseID(args[0])
+}
+
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid id %q", s)
+	}
+	return id, nil
+}
+
+// parseIDs reads a comma separated list of ids
+func parseIDs(s string) ([]int, error) {
+	var ids []int
+	if len(s) == 0 {
+		return ids, nil
+	}
+	for _, f := range strings.Split(s, ",") {
+		id, err := parseID(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// timeFlag is a date, 2006-01-02, RFC3339 time, or today, tomorrow or
+// yesterday
+type timeFlag struct {
+	t   *time.Time
+	loc *time.Location
+	now func() time.Time
+}
+
+func (a *app) timeFlag(t *time.Time) timeFlag {
+	return timeFlag{t: t, loc: a.loc, now: a.now}
+}
+
+func (a *app) timeVar(fs *flag.FlagSet, t *time.Time, name string, usage string) {
+	fs.Var(a.timeFlag(t), name, usage+" (2006-01-02, RFC3339, today or tomorrow)")
+}
+
+// today is midnight of the current day
+func (a *app) today() time.Time {
+	var t time.Time
+	a.timeFlag(&t).Set("today")
+	return t
+}
+
+func (f timeFlag) String() string {
+	if f.t == nil || f.t.IsZero() {
+		return ""
 	}
+	return f.t.Format(time.RFC3339)
+}
 
-	// get next other cross fit
-	slots, err = client.SlotNextDate("394")
+var relativeDays = map[string]int{"yesterday": -1, "today": 0, "tomorrow": 1}
+
+func (f timeFlag) Set(s string) error {
+	if days, ok := relativeDays[s]; ok {
+		now := time.Now
+		if f.now != nil {
+			now = f.now
+		}
+		y, m, d := now().In(f.loc).Date()
+		*f.t = time.Date(y, m, d+days, 0, 0, 0, 0, f.loc)
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.ParseInLocation("2006-01-02", s, f.loc)
+	}
 	if err != nil {
-		// log.Fatal(err)
+		return fmt.Errorf("invalid time %q", s)
 	}
+	*f.t = t
+	return nil
+}
 
-	// get next running
-	slots, err = client.SlotNextDate("395")
+// input reads the JSON passed to create and update into v. data is used
+// when set, otherwise file, where "-" is stdin.
+func (a *app) input(data string, file string, v interface{}) error {
+	var bs []byte
+	var err error
+	switch {
+	case len(data) > 0:
+		bs = []byte(data)
+	case file == "-":
+		bs, err = ioutil.ReadAll(a.in)
+	default:
+		bs, err = ioutil.ReadFile(file)
+	}
 	if err != nil {
-		// log.Fatal(err)
+		return err
 	}
-	for _, slot := range slots {
-		fmt.Println("Next running", slot)
+	// Misspelled fields would be dropped silently
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid input: %s", err)
 	}
+	return nil
 }
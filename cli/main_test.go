@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drewwells/makeplans"
+	"github.com/drewwells/makeplans/makeplanstest"
+)
+
+func testApp(t *testing.T) (*makeplanstest.Server, *app, *bytes.Buffer) {
+	srv := makeplanstest.NewServer()
+	out := &bytes.Buffer{}
+	a := &app{
+		api: srv.Client(),
+		in:  strings.NewReader(""),
+		out: out,
+		err: &bytes.Buffer{},
+		loc: time.UTC,
+	}
+	a.output.Set("json")
+	return srv, a, out
+}
+
+func TestCLI_services(t *testing.T) {
+	srv, a, out := testApp(t)
+	defer srv.Close()
+
+	err := a.run([]string{"services", "create", "-d", `{"title":"Yoga","interval":60,"active":true}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var svc makeplans.Service
+	if err := json.Unmarshal(out.Bytes(), &svc); err != nil {
+		t.Fatal(err)
+	}
+	if e := "Yoga"; svc.Title != e {
+		t.Errorf("got: %s wanted: %s", svc.Title, e)
+	}
+
+	out.Reset()
+	a.in = strings.NewReader(`{"title":"Hot Yoga"}`)
+	err = a.run([]string{"services", "update", strconv.Itoa(svc.ID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var updated makeplans.Service
+	if err := json.Unmarshal(out.Bytes(), &updated); err != nil {
+		t.Fatal(err)
+	}
+	if e := "Hot Yoga"; updated.Title != e {
+		t.Errorf("got: %s wanted: %s", updated.Title, e)
+	}
+	if e := 60; updated.Interval != e {
+		t.Errorf("got: %d wanted: %d", updated.Interval, e)
+	}
+
+	out.Reset()
+	if err := a.run([]string{"services", "get", strconv.Itoa(svc.ID)}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Hot Yoga") {
+		t.Errorf("got: %s wanted Hot Yoga", out)
+	}
+
+	err = a.run([]string{"services", "get", "999"})
+	if err == nil || !strings.Contains(err.Error(), "service 999") {
+		t.Errorf("got: %v wanted not found", err)
+	}
+}
+
+func TestCLI_bookings(t *testing.T) {
+	srv, a, out := testApp(t)
+	defer srv.Close()
+	b := srv.AddBooking(makeplans.Booking{
+		ServiceID:  1,
+		ResourceID: 2,
+		State:      makeplans.BookingConfirmed,
+	})
+	srv.AddBooking(makeplans.Booking{ServiceID: 3, ResourceID: 2})
+
+	err := a.run([]string{"bookings", "list", "-service", "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bookings []makeplans.Booking
+	if err := json.Unmarshal(out.Bytes(), &bookings); err != nil {
+		t.Fatal(err)
+	}
+	if e := 1; len(bookings) != e {
+		t.Fatalf("got: %d wanted: %d", len(bookings), e)
+	}
+
+	out.Reset()
+	if err := a.run([]string{"bookings", "cancel", strconv.Itoa(b.ID)}); err != nil {
+		t.Fatal(err)
+	}
+	var cancelled makeplans.Booking
+	if err := json.Unmarshal(out.Bytes(), &cancelled); err != nil {
+		t.Fatal(err)
+	}
+	if e := makeplans.BookingCancelled; cancelled.State != e {
+		t.Errorf("got: %s wanted: %s", cancelled.State, e)
+	}
+}
+
+func TestCLI_input(t *testing.T) {
+	srv, a, _ := testApp(t)
+	defer srv.Close()
+
+	err := a.run([]string{"people", "create", "-d", `{"name":"Ann","phone_number":"555-0100"}`})
+	if err == nil || !strings.Contains(err.Error(), `unknown field "phone_number"`) {
+		t.Errorf("got: %v wanted unknown field", err)
+	}
+
+	err = a.run([]string{"bookings", "list", "-all", "-service", "1"})
+	if e := "-all can't be combined with -service"; err == nil || err.Error() != e {
+		t.Errorf("got: %v wanted: %s", err, e)
+	}
+}
+
+func TestCLI_usage(t *testing.T) {
+	srv, a, _ := testApp(t)
+	defer srv.Close()
+
+	for _, args := range [][]string{
+		nil,
+		{"nope"},
+		{"events"},
+		{"events", "delete", "1"},
+		{"bookings", "get", "-x"},
+	} {
+		if err := a.run(args); err != errUsage {
+			t.Errorf("%v got: %v wanted: %v", args, err, errUsage)
+		}
+	}
+
+	err := a.run([]string{"bookings", "get", "abc"})
+	if e := `invalid id "abc"`; err == nil || err.Error() != e {
+		t.Errorf("got: %v wanted: %s", err, e)
+	}
+}
+
+func TestCLI_output(t *testing.T) {
+	srv, a, out := testApp(t)
+	defer srv.Close()
+	srv.AddService(makeplans.Service{ID: 393, Title: "Cross fit", Active: true,
+		Interval: 60, Price: &makeplans.Money{Amount: 2000}})
+	srv.AddService(makeplans.Service{ID: 2044912747, Title: "Running, outside",
+		Active: true, Interval: 30})
+
+	for _, tc := range []struct {
+		args []string
+		e    string
+	}{
+		{
+			[]string{"services", "list", "-o", "table"},
+			"ID          ACTIVE  INTERVAL  PRICE  TITLE\n" +
+				"393         true    60        20.00  Cross fit\n" +
+				"2044912747  true    30               Running, outside\n",
+		},
+		{
+			[]string{"services", "get", "393", "-o", "table"},
+			"id:        393\nactive:    true\ninterval:  60\nprice:     20.00\ntitle:     Cross fit\n",
+		},
+		{
+			[]string{"services", "list", "-o", "template={{.ID}} {{.Title}}"},
+			"393 Cross fit\n2044912747 Running, outside\n",
+		},
+		{
+			[]string{"services", "get", "2044912747", "--output", "yaml"},
+			"active: true\nid: 2044912747\ninterval: 30\ntitle: Running, outside\n",
+		},
+	} {
+		out.Reset()
+		if err := a.run(tc.args); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != tc.e {
+			t.Errorf("%v got:\n%s\nwanted:\n%s", tc.args, out, tc.e)
+		}
+	}
+
+	out.Reset()
+	if err := a.run([]string{"services", "list", "-o", "csv"}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if e := 4; len(lines) != e {
+		t.Fatalf("got: %d wanted: %d", len(lines), e)
+	}
+	if e := `"Running, outside"`; !strings.Contains(lines[2], e) {
+		t.Errorf("got: %s wanted: %s", lines[2], e)
+	}
+
+	err := a.run([]string{"services", "list", "-o", "xml"})
+	if err != errUsage {
+		t.Errorf("got: %v wanted: %v", err, errUsage)
+	}
+}
+
+func TestCLI_tableInclude(t *testing.T) {
+	srv, a, out := testApp(t)
+	defer srv.Close()
+	from := time.Date(2015, 12, 14, 9, 0, 0, 0, time.UTC)
+	svc := srv.AddService(makeplans.Service{Title: "Yoga", Active: true})
+	r := srv.AddResource(makeplans.Resource{Title: "Room"})
+	p := srv.AddPerson(makeplans.Person{Name: "Jane"})
+	srv.AddBooking(makeplans.Booking{ID: 12, ServiceID: svc.ID, ResourceID: r.ID,
+		PersonID: p.ID, BookedFrom: &from, State: makeplans.BookingConfirmed})
+
+	// Embedded models don't fit in a cell
+	err := a.run([]string{"bookings", "list", "-include", "person,service,resource", "-o", "table"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := "BOOKED_FROM           ID  PERSON_ID  RESOURCE_ID  SERVICE_ID  STATE\n" +
+		"2015-12-14T09:00:00Z  12  " + strconv.Itoa(p.ID) + "          " + strconv.Itoa(r.ID) +
+		"            " + strconv.Itoa(svc.ID) + "           confirmed\n"
+	if out.String() != e {
+		t.Errorf("got:\n%s\nwanted:\n%s", out, e)
+	}
+}
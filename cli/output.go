@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/drewwells/makeplans"
+	"gopkg.in/yaml.v2"
+)
+
+// output is the format results are printed in: table, json, yaml, csv or
+// template=<text/template>
+type output struct {
+	format string
+	tmpl   *template.Template
+}
+
+var formats = []string{"table", "json", "yaml", "csv", "template=..."}
+
+func (o *output) String() string {
+	if o == nil || len(o.format) == 0 {
+		return "table"
+	}
+	return o.format
+}
+
+func (o *output) Set(s string) error {
+	if text := strings.TrimPrefix(s, "template="); text != s {
+		t, err := template.New("output").Parse(text)
+		if err != nil {
+			return err
+		}
+		o.format, o.tmpl = "template", t
+		return nil
+	}
+	switch s {
+	case "table", "json", "yaml", "csv":
+		o.format, o.tmpl = s, nil
+		return nil
+	}
+	return fmt.Errorf("unknown output %q, use one of %s", s, strings.Join(formats, ", "))
+}
+
+// outputVar registers -o and -output on fs
+func (a *app) outputVar(fs *flag.FlagSet) {
+	usage := "output format: " + strings.Join(formats, ", ")
+	fs.Var(&a.output, "output", usage)
+	fs.Var(&a.output, "o", usage)
+}
+
+// print writes v, a model or slice of models, in the selected format
+func (a *app) print(v interface{}) error {
+	switch a.output.format {
+	case "json":
+		enc := json.NewEncoder(a.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "yaml":
+		generic, err := plain(v)
+		if err != nil {
+			return err
+		}
+		bs, err := yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		_, err = a.out.Write(bs)
+		return err
+	case "template":
+		return a.printTemplate(v)
+	}
+	t := a.table(v)
+	if a.output.format == "csv" {
+		return writeCSV(a.out, t)
+	}
+	if !t.list && len(t.rows) == 1 {
+		return writeFields(a.out, t)
+	}
+	return writeTable(a.out, t)
+}
+
+// printTemplate executes the template once per element of a slice, or once
+// for any other value, ending each with a newline
+func (a *app) printTemplate(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return a.execute(v)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := a.execute(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *app) execute(v interface{}) error {
+	if err := a.output.tmpl.Execute(a.out, v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(a.out, "\n")
+	return err
+}
+
+// plain converts v to maps and slices keyed by the json names. Numbers are
+// kept as integers where possible so ids don't turn into floats.
+func plain(v interface{}) (interface{}, error) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return numbers(generic), nil
+}
+
+func numbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = numbers(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = numbers(e)
+		}
+	}
+	return v
+}
+
+// column is a model field printed by table and csv
+type column struct {
+	name  string
+	index []int
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// scalarTypes marshal themselves as a single value. Models such as
+// Resource marshal themselves too, as objects that don't fit in a cell.
+var scalarTypes = map[reflect.Type]bool{
+	timeType:                                 true,
+	reflect.TypeOf(makeplans.Money{}):        true,
+	reflect.TypeOf(makeplans.Notification{}): true,
+	reflect.TypeOf(makeplans.Reminder{}):     true,
+	reflect.TypeOf(makeplans.Template{}):     true,
+}
+
+// columns lists the fields of struct type t that fit in a cell: scalars,
+// times, slices of scalars and the scalarTypes. Embedded structs are
+// flattened, nested models and custom data are left out.
+func columns(t reflect.Type, index []int) []column {
+	var cols []column
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if len(f.PkgPath) > 0 {
+			continue
+		}
+		idx := append(append([]int{}, index...), i)
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && ft.Kind() == reflect.Struct {
+			cols = append(cols, columns(ft, idx)...)
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); len(tag) > 0 {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; len(n) > 0 {
+				name = n
+			}
+		}
+		if cell(ft) {
+			cols = append(cols, column{name: name, index: idx})
+		}
+	}
+	return cols
+}
+
+func cell(t reflect.Type) bool {
+	if scalarTypes[t] {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Interface:
+		return false
+	case reflect.Slice:
+		return cell(t.Elem()) && t.Elem().Kind() != reflect.Slice
+	}
+	return true
+}
+
+// table is a value flattened into cells
+type table struct {
+	cols []column
+	rows [][]string
+	// used marks columns set in at least one row
+	used []bool
+	// list is set when the value was a slice
+	list bool
+}
+
+func (a *app) table(v interface{}) table {
+	var tbl table
+	rv := reflect.ValueOf(v)
+	var elems []reflect.Value
+	if rv.Kind() == reflect.Slice {
+		tbl.list = true
+		for i := 0; i < rv.Len(); i++ {
+			elems = append(elems, rv.Index(i))
+		}
+	} else {
+		elems = append(elems, rv)
+	}
+	t := rv.Type()
+	if tbl.list {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		// Plain values print as a single column
+		tbl.cols = []column{{name: "value"}}
+		tbl.used = []bool{true}
+		for _, e := range elems {
+			tbl.rows = append(tbl.rows, []string{a.format(e)})
+		}
+		return tbl
+	}
+	tbl.cols = columns(t, nil)
+	tbl.used = make([]bool, len(tbl.cols))
+	for _, e := range elems {
+		e = reflect.Indirect(e)
+		row := make([]string, len(tbl.cols))
+		for i, col := range tbl.cols {
+			f, err := e.FieldByIndexErr(col.index)
+			if err != nil {
+				continue
+			}
+			row[i] = a.format(f)
+			if !f.IsZero() {
+				tbl.used[i] = true
+			}
+		}
+		tbl.rows = append(tbl.rows, row)
+	}
+	return tbl
+}
+
+// format renders a single cell. Times are shown in the local zone.
+func (a *app) format(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch {
+	case v.Type() == timeType:
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		return t.In(a.loc).Format(time.RFC3339)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = a.format(v.Index(i))
+		}
+		return strings.Join(parts, ",")
+	case v.CanInterface():
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			return s.String()
+		}
+		if m, ok := v.Interface().(json.Marshaler); ok {
+			bs, err := m.MarshalJSON()
+			if err != nil {
+				return ""
+			}
+			var s string
+			if json.Unmarshal(bs, &s) == nil {
+				return s
+			}
+			return string(bs)
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// writeCSV writes every column so the layout is the same for each call
+func writeCSV(w io.Writer, t table) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(t.cols))
+	for i, col := range t.cols {
+		header[i] = col.name
+	}
+	cw.Write(header)
+	cw.WriteAll(t.rows)
+	return cw.Error()
+}
+
+// writeTable prints rows aligned under a header. Columns unset in every
+// row are left out to keep the table narrow.
+func writeTable(w io.Writer, t table) error {
+	var keep []int
+	for i := range t.cols {
+		if t.used[i] || len(t.rows) == 0 {
+			keep = append(keep, i)
+		}
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	header := make([]string, len(keep))
+	for i, c := range keep {
+		header[i] = strings.ToUpper(t.cols[c].name)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range t.rows {
+		cells := make([]string, len(keep))
+		for i, c := range keep {
+			cells[i] = row[c]
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeFields prints a single model as one field per line, skipping unset
+// fields
+func writeFields(w io.Writer, t table) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for i, col := range t.cols {
+		if t.used[i] {
+			fmt.Fprintf(tw, "%s:\t%s\n", col.name, t.rows[0][i])
+		}
+	}
+	return tw.Flush()
+}
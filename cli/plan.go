@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/drewwells/makeplans"
+	"gopkg.in/yaml.v2"
+)
+
+// configFiles registers the flags naming the config and state files
+func configFiles(fs *flag.FlagSet) (cfgPath *string, statePath *string) {
+	cfgPath = fs.String("f", "makeplans.yaml", "account configuration")
+	statePath = fs.String("state", "", "state file, defaults to the config file with .state.json")
+	return
+}
+
+// readPlan reads the config and state and plans the changes
+func (a *app) readPlan(cfgPath string, statePath *string) (*makeplans.ConfigPlan, error) {
+	if len(*statePath) == 0 {
+		*statePath = strings.TrimSuffix(cfgPath, filepath.Ext(cfgPath)) + ".state.json"
+	}
+	bs, err := ioutil.ReadFile(cfgPath)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(bs, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %s", cfgPath, err)
+	}
+	// yaml.v2 decodes maps with interface keys, JSON needs strings
+	if bs, err = json.Marshal(stringKeys(doc)); err != nil {
+		return nil, fmt.Errorf("%s: %s", cfgPath, err)
+	}
+	var cfg makeplans.AccountConfig
+	if err := json.Unmarshal(bs, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %s", cfgPath, err)
+	}
+
+	var state makeplans.ConfigState
+	bs, err = ioutil.ReadFile(*statePath)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(bs, &state); err != nil {
+			return nil, fmt.Errorf("%s: %s", *statePath, err)
+		}
+	}
+	return makeplans.PlanConfig(a.api, cfg, state)
+}
+
+func stringKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = stringKeys(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = stringKeys(e)
+		}
+	}
+	return v
+}
+
+func (a *app) printPlan(plan *makeplans.ConfigPlan) error {
+	if a.output.String() != "table" {
+		return a.print(plan.Changes)
+	}
+	_, err := fmt.Fprintln(a.out, plan)
+	return err
+}
+
+// plan shows the changes apply would make
+func (a *app) plan(args []string) error {
+	fs := a.flags("plan")
+	cfgPath, statePath := configFiles(fs)
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 0 {
+		return fmt.Errorf("unexpected arguments %v", pos)
+	}
+	plan, err := a.readPlan(*cfgPath, statePath)
+	if err != nil {
+		return err
+	}
+	return a.printPlan(plan)
+}
+
+// apply makes the account match the config after confirming the plan, the
+// state is saved even when a change fails
+func (a *app) apply(args []string) error {
+	fs := a.flags("apply")
+	cfgPath, statePath := configFiles(fs)
+	yes := fs.Bool("yes", false, "apply without asking")
+	pos, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 0 {
+		return fmt.Errorf("unexpected arguments %v", pos)
+	}
+	plan, err := a.readPlan(*cfgPath, statePath)
+	if err != nil {
+		return err
+	}
+	if err := a.printPlan(plan); err != nil {
+		return err
+	}
+	if len(plan.Changes) == 0 {
+		return nil
+	}
+	if !*yes {
+		ok, err := a.prompter().confirm("Apply", false)
+		if err != nil || !ok {
+			return err
+		}
+	}
+	state, applyErr := makeplans.ApplyConfig(a.api, plan)
+	bs, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(*statePath, append(bs, '\n'), 0644); err != nil {
+		return err
+	}
+	if applyErr != nil {
+		return applyErr
+	}
+	fmt.Fprintf(a.err, "applied %d changes\n", len(plan.Changes))
+	return nil
+}
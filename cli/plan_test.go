@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drewwells/makeplans"
+)
+
+func TestCLI_planApply(t *testing.T) {
+	srv, a, out := testApp(t)
+	defer srv.Close()
+	a.output.Set("table")
+
+	dir := t.TempDir()
+	cfg := filepath.Join(dir, "gym.yaml")
+	err := ioutil.WriteFile(cfg, []byte(`services:
+  yoga:
+    title: Yoga
+    interval: 60
+    active: true
+resources:
+  studio:
+    title: Studio
+    opening_hours_mon: ["08:00", "16:00"]
+    provides: [yoga]
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.run([]string{"plan", "-f", cfg}); err != nil {
+		t.Fatal(err)
+	}
+	if e := "Plan: 3 to create, 0 to update, 0 to remove."; !strings.Contains(out.String(), e) {
+		t.Errorf("got:\n%s\nwanted: %s", out, e)
+	}
+
+	out.Reset()
+	a.in = strings.NewReader("y\n")
+	if err := a.run([]string{"apply", "-f", cfg}); err != nil {
+		t.Fatal(err)
+	}
+	bs, err := ioutil.ReadFile(filepath.Join(dir, "gym.state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state makeplans.ConfigState
+	if err := json.Unmarshal(bs, &state); err != nil {
+		t.Fatal(err)
+	}
+	providers, err := srv.Client().Providers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(providers) != 1 || providers[0].ServiceID != state.Services["yoga"] ||
+		providers[0].ResourceID != state.Resources["studio"] {
+		t.Errorf("got: %+v state: %+v", providers, state)
+	}
+
+	out.Reset()
+	if err := a.run([]string{"apply", "-f", cfg, "-yes"}); err != nil {
+		t.Fatal(err)
+	}
+	if e := "No changes, the account matches the config.\n"; out.String() != e {
+		t.Errorf("got: %q wanted: %q", out.String(), e)
+	}
+	if e := "Apply [y/N]: applied 3 changes\n"; a.err.(*bytes.Buffer).String() != e {
+		t.Errorf("got: %q wanted: %q", a.err.(*bytes.Buffer).String(), e)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// errInputClosed is returned when stdin ends while prompting
+var errInputClosed = errors.New("input closed")
+
+// prompter asks questions on a plain terminal, one answer per line. Invalid
+// answers are asked again.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	// tty is set when the input is a terminal, secrets are read from it
+	// without echo
+	tty *os.File
+}
+
+// prompter prompts on stderr so stdout only holds results
+func (a *app) prompter() *prompter {
+	p := &prompter{in: bufio.NewReader(a.in), out: a.err}
+	if f, ok := a.in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		p.tty = f
+	}
+	return p
+}
+
+// read prints the prompt and reads a line
+func (p *prompter) read(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	s, err := p.in.ReadString('\n')
+	s = strings.TrimSpace(s)
+	switch {
+	case err == io.EOF && len(s) == 0:
+		return "", errInputClosed
+	case err != nil && err != io.EOF:
+		return "", err
+	}
+	return s, nil
+}
+
+// line reads an answer, def is returned for an empty answer
+func (p *prompter) line(label string, def string) (string, error) {
+	prompt := label + ": "
+	if len(def) > 0 {
+		prompt = fmt.Sprintf("%s [%s]: ", label, def)
+	}
+	s, err := p.read(prompt)
+	if err != nil || len(s) > 0 {
+		return s, err
+	}
+	return def, nil
+}
+
+// required reads an answer until one is given
+func (p *prompter) required(label string) (string, error) {
+	for {
+		s, err := p.line(label, "")
+		if err != nil || len(s) > 0 {
+			return s, err
+		}
+		fmt.Fprintf(p.out, "%s is required\n", label)
+	}
+}
+
+// secret reads a required value without echoing it on a terminal
+func (p *prompter) secret(label string) (string, error) {
+	if p.tty == nil {
+		return p.required(label)
+	}
+	for {
+		fmt.Fprint(p.out, label+": ")
+		bs, err := term.ReadPassword(int(p.tty.Fd()))
+		fmt.Fprintln(p.out)
+		if err != nil {
+			return "", err
+		}
+		if s := strings.TrimSpace(string(bs)); len(s) > 0 {
+			return s, nil
+		}
+		fmt.Fprintf(p.out, "%s is required\n", label)
+	}
+}
+
+// choose reads a number between 1 and n and returns it
+func (p *prompter) choose(label string, n int, def int) (int, error) {
+	var d string
+	if def > 0 {
+		d = strconv.Itoa(def)
+	}
+	for {
+		s, err := p.line(label, d)
+		if err != nil {
+			return 0, err
+		}
+		if i, err := strconv.Atoi(s); err == nil && i >= 1 && i <= n {
+			return i, nil
+		}
+		fmt.Fprintf(p.out, "enter a number from 1 to %d\n", n)
+	}
+}
+
+// confirm reads a yes or no answer
+func (p *prompter) confirm(label string, def bool) (bool, error) {
+	prompt := label + " [y/N]: "
+	if def {
+		prompt = label + " [Y/n]: "
+	}
+	for {
+		s, err := p.read(prompt)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(s) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "answer y or n")
+	}
+}
@@ -0,0 +1,221 @@
+package makeplans
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// CustomData holds the account specific fields Makeplans stores on
+// bookings, people, events, resources and services. Makeplans may return
+// values as strings, ie. 5 is read back as "5", the accessors convert
+// them.
+type CustomData map[string]interface{}
+
+// ErrCustomDataMissing is returned when a custom field isn't set
+var ErrCustomDataMissing = errors.New("custom data field missing")
+
+// Set stores a value, creating the map if needed
+func (cd *CustomData) Set(key string, v interface{}) {
+	if *cd == nil {
+		*cd = CustomData{}
+	}
+	(*cd)[key] = v
+}
+
+// Bind decodes the custom data into v, a pointer to a struct with json
+// tags. Fields are decoded one by one like CustomValue, so values stored
+// as strings by Makeplans are parsed.
+func (cd CustomData) Bind(v interface{}) error {
+	keys := make([]string, 0, len(cd))
+	for key := range cd {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		err = decodeCustom(cd[key], func(bs []byte) error {
+			return json.Unmarshal([]byte(fmt.Sprintf("{%s:%s}", name, bs)), v)
+		})
+		if err != nil {
+			return fmt.Errorf("custom data %s: %s", key, err)
+		}
+	}
+	return nil
+}
+
+// NewCustomData converts a struct with json tags into custom data
+func NewCustomData(v interface{}) (CustomData, error) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var cd CustomData
+	err = json.Unmarshal(bs, &cd)
+	return cd, err
+}
+
+// CustomValue converts the value at key to T. Values stored as strings by
+// Makeplans are parsed, so "5" is returned as an int.
+func CustomValue[T any](cd CustomData, key string) (T, error) {
+	var ret T
+	v, ok := cd[key]
+	if !ok || v == nil {
+		return ret, ErrCustomDataMissing
+	}
+	if t, ok := v.(T); ok {
+		return t, nil
+	}
+	err := decodeCustom(v, func(bs []byte) error {
+		ret = *new(T)
+		return json.Unmarshal(bs, &ret)
+	})
+	if err != nil {
+		return ret, fmt.Errorf("custom data %s: %s", key, err)
+	}
+	return ret, nil
+}
+
+// decodeCustom passes the JSON of v to decode. Makeplans stringifies
+// values, when v doesn't decode the string contents are tried.
+func decodeCustom(v interface{}, decode func([]byte) error) error {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err = decode(bs); err == nil {
+		return nil
+	}
+	if s, ok := v.(string); ok && decode([]byte(s)) == nil {
+		return nil
+	}
+	return err
+}
+
+// HasCustomData is implemented by every model carrying custom data
+type HasCustomData interface {
+	customData() CustomData
+}
+
+func (b Booking) customData() CustomData  { return b.CustomData }
+func (p Person) customData() CustomData   { return p.CustomData }
+func (e Event) customData() CustomData    { return e.CustomData }
+func (r Resource) customData() CustomData { return r.CustomData }
+func (s Service) customData() CustomData  { return s.CustomData }
+
+// GetCustomData binds the custom data of a model to T
+//
+//	fields, err := makeplans.GetCustomData[MyFields](booking)
+func GetCustomData[T any](m HasCustomData) (T, error) {
+	var ret T
+	err := m.customData().Bind(&ret)
+	return ret, err
+}
+
+// CustomFieldType is the JSON type of a custom field
+type CustomFieldType string
+
+const (
+	CustomString CustomFieldType = "string"
+	CustomNumber CustomFieldType = "number"
+	CustomBool   CustomFieldType = "bool"
+	CustomList   CustomFieldType = "list"
+	CustomObject CustomFieldType = "object"
+)
+
+// CustomField describes a single custom data field
+type CustomField struct {
+	Type     CustomFieldType
+	Required bool
+}
+
+// CustomDataSchema describes the custom data fields of a model, fields not
+// in the schema are rejected
+type CustomDataSchema map[string]CustomField
+
+func customType(v interface{}) CustomFieldType {
+	switch v.(type) {
+	case string:
+		return CustomString
+	case bool:
+		return CustomBool
+	case float32, float64, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, json.Number:
+		return CustomNumber
+	case map[string]interface{}, CustomData:
+		return CustomObject
+	}
+	// Slices of any type and structs are compared after a round trip
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	var generic interface{}
+	if json.Unmarshal(bs, &generic) != nil {
+		return ""
+	}
+	switch generic.(type) {
+	case []interface{}:
+		return CustomList
+	case map[string]interface{}:
+		return CustomObject
+	}
+	return customType(generic)
+}
+
+// Validate checks cd against the schema. Problems are reported as a
+// FieldError keyed by "custom_data.<field>".
+func (s CustomDataSchema) Validate(cd CustomData) error {
+	fe := FieldError{}
+	var keys []string
+	for key := range s {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		field := s[key]
+		v, ok := cd[key]
+		if !ok || v == nil {
+			if field.Required {
+				fe["custom_data."+key] = []string{"can't be blank"}
+			}
+			continue
+		}
+		got := customType(v)
+		// Makeplans stringifies values, "5" is a number
+		if s, ok := v.(string); ok && field.Type != CustomString {
+			var parsed interface{}
+			if json.Unmarshal([]byte(s), &parsed) == nil {
+				got = customType(parsed)
+			}
+		}
+		if got != field.Type {
+			fe["custom_data."+key] = []string{
+				fmt.Sprintf("must be a %s, got %s", field.Type, got),
+			}
+		}
+	}
+	for key := range cd {
+		if _, ok := s[key]; !ok {
+			fe["custom_data."+key] = []string{"is not a known field"}
+		}
+	}
+	if len(fe) > 0 {
+		return fe
+	}
+	return nil
+}
+
+// validateCustomData checks custom data against the schema registered in
+// Client.Schemas for the model, ie. "booking"
+func (c *Client) validateCustomData(model string, cd CustomData) error {
+	schema, ok := c.Schemas[model]
+	if !ok {
+		return nil
+	}
+	return schema.Validate(cd)
+}
@@ -0,0 +1,122 @@
+package makeplans
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type testFields struct {
+	Poop   string   `json:"poop"`
+	Number int      `json:"number"`
+	Slice  []string `json:"slice"`
+}
+
+func TestCustomData_get(t *testing.T) {
+	var b wrapBooking
+	err := json.Unmarshal(bookingCancelResponse, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cd := b.Booking.CustomData
+
+	// Makeplans returns "5"
+	n, err := CustomValue[int](cd, "number")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 5; n != e {
+		t.Errorf("got: %d wanted: %d", n, e)
+	}
+
+	slice, err := CustomValue[[]string](cd, "slice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 3; len(slice) != e {
+		t.Errorf("got: %d wanted: %d", len(slice), e)
+	}
+
+	if _, err := CustomValue[string](cd, "missing"); err != ErrCustomDataMissing {
+		t.Errorf("got: %v wanted: %s", err, ErrCustomDataMissing)
+	}
+
+	if _, err := CustomValue[bool](cd, "poop"); err == nil {
+		t.Error("expected conversion error")
+	}
+}
+
+func TestCustomData_bind(t *testing.T) {
+	var b wrapBooking
+	err := json.Unmarshal(bookingCancelResponse, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Makeplans returns "5" and "[\"a\", \"b\", \"c\"]"
+	fields, err := GetCustomData[testFields](b.Booking)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "shoot"; fields.Poop != e {
+		t.Errorf("got: %s wanted: %s", fields.Poop, e)
+	}
+	if e := 5; fields.Number != e {
+		t.Errorf("got: %d wanted: %d", fields.Number, e)
+	}
+	if e := 3; len(fields.Slice) != e {
+		t.Errorf("got: %d wanted: %d", len(fields.Slice), e)
+	}
+
+	var p Person
+	p.CustomData.Set("number", "five")
+	if _, err := GetCustomData[testFields](p); err == nil {
+		t.Error("expected conversion error")
+	}
+
+	cd, err := NewCustomData(testFields{Poop: "a", Slice: []string{"b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "a"; cd["poop"] != e {
+		t.Errorf("got: %v wanted: %s", cd["poop"], e)
+	}
+}
+
+func TestCustomData_schema(t *testing.T) {
+	schema := CustomDataSchema{
+		"poop":   {Type: CustomString, Required: true},
+		"number": {Type: CustomNumber},
+		"slice":  {Type: CustomList},
+	}
+
+	ok := CustomData{"poop": "shoot", "number": 5, "slice": []string{"a"}}
+	if err := schema.Validate(ok); err != nil {
+		t.Error(err)
+	}
+
+	// As returned by Makeplans
+	var b wrapBooking
+	if err := json.Unmarshal(bookingCancelResponse, &b); err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Validate(b.Booking.CustomData); err != nil {
+		t.Error(err)
+	}
+
+	err := schema.Validate(CustomData{"number": "five", "extra": true})
+	fe, isFE := err.(FieldError)
+	if !isFE {
+		t.Fatalf("got: %v wanted FieldError", err)
+	}
+	for _, key := range []string{"custom_data.poop", "custom_data.number", "custom_data.extra"} {
+		if _, found := fe[key]; !found {
+			t.Errorf("expected error for %s got: %s", key, fe)
+		}
+	}
+
+	client := &Client{Schemas: map[string]CustomDataSchema{"booking": schema}}
+	_, err = client.MakeBooking(Booking{CustomData: CustomData{"number": 5}})
+	if _, isFE := err.(FieldError); !isFE {
+		t.Errorf("got: %v wanted FieldError", err)
+	}
+}
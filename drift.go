@@ -0,0 +1,179 @@
+package makeplans
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DriftKind tells an unknown field apart from a field of the wrong type
+type DriftKind string
+
+const (
+	// DriftUnknown is a field Makeplans sent that no model field maps to
+	DriftUnknown DriftKind = "unknown"
+	// DriftType is a field that failed to unmarshal into the model field
+	DriftType DriftKind = "type"
+)
+
+// Drift is a difference between a Makeplans response and the models
+type Drift struct {
+	// Model is the wrapper key, nested models are joined by dots, ie.
+	// "booking.person"
+	Model string
+	Field string
+	Kind  DriftKind
+	// Value is the raw JSON sent by Makeplans
+	Value json.RawMessage
+	// Err is the unmarshal error for DriftType
+	Err error
+}
+
+func (d Drift) String() string {
+	if d.Kind == DriftType {
+		return fmt.Sprintf("%s.%s: %s", d.Model, d.Field, d.Err)
+	}
+	return fmt.Sprintf("%s.%s: unknown field %s", d.Model, d.Field, d.Value)
+}
+
+// driftModels maps the wrapper keys used by Makeplans to their models
+var driftModels = map[string]reflect.Type{
+	"booking":  reflect.TypeOf(Booking{}),
+	"event":    reflect.TypeOf(Event{}),
+	"person":   reflect.TypeOf(Person{}),
+	"provider": reflect.TypeOf(Provider{}),
+	"resource": reflect.TypeOf(Resource{}),
+	"service":  reflect.TypeOf(Service{}),
+	"slot":     reflect.TypeOf(Slot{}),
+}
+
+// CheckDrift compares a Makeplans response body against the models. Both
+// wrapped objects, {"booking": {...}}, and lists of them are understood,
+// other bodies report nothing.
+func CheckDrift(bs []byte) []Drift {
+	var list []map[string]json.RawMessage
+	if json.Unmarshal(bs, &list) != nil {
+		var one map[string]json.RawMessage
+		if json.Unmarshal(bs, &one) != nil {
+			return nil
+		}
+		list = append(list, one)
+	}
+	var drift []Drift
+	for _, wrap := range list {
+		if len(wrap) != 1 {
+			continue
+		}
+		for key, raw := range wrap {
+			t, ok := driftModels[key]
+			if !ok {
+				continue
+			}
+			drift = append(drift, checkStruct(key, t, raw)...)
+		}
+	}
+	return drift
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// jsonFields maps json names to struct fields of t
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if len(f.PkgPath) > 0 {
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); len(tag) > 0 {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; len(n) > 0 {
+				name = n
+			}
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+func checkStruct(model string, t reflect.Type, raw json.RawMessage) []Drift {
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(raw, &obj) != nil {
+		return nil
+	}
+	fields := jsonFields(t)
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var drift []Drift
+	for _, key := range keys {
+		val := obj[key]
+		f, ok := fields[key]
+		if !ok {
+			drift = append(drift, Drift{
+				Model: model, Field: key, Kind: DriftUnknown, Value: val,
+			})
+			continue
+		}
+		if string(val) == "null" {
+			continue
+		}
+		ptr := reflect.New(f.Type)
+		if err := json.Unmarshal(val, ptr.Interface()); err != nil {
+			drift = append(drift, Drift{
+				Model: model, Field: key, Kind: DriftType, Value: val, Err: err,
+			})
+			continue
+		}
+		drift = append(drift, checkNested(model+"."+key, f.Type, val)...)
+	}
+	return drift
+}
+
+// checkNested descends into struct, pointer to struct and slice of struct
+// fields. Types decoding themselves are trusted.
+func checkNested(model string, t reflect.Type, raw json.RawMessage) []Drift {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType || reflect.PtrTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return checkStruct(model, t, raw)
+	case reflect.Slice:
+		var elems []json.RawMessage
+		if json.Unmarshal(raw, &elems) != nil {
+			return nil
+		}
+		var drift []Drift
+		for _, e := range elems {
+			drift = append(drift, checkNested(model, t.Elem(), e)...)
+		}
+		return drift
+	}
+	return nil
+}
+
+// reportDrift passes drift in the response to Client.Drift
+func (c *Client) reportDrift(bs []byte) {
+	if c.Drift == nil {
+		return
+	}
+	for _, d := range CheckDrift(bs) {
+		c.Drift(d)
+	}
+}
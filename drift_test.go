@@ -0,0 +1,50 @@
+package makeplans
+
+import "testing"
+
+func TestDrift_client(t *testing.T) {
+	_, client := mockServerClient(t)
+	var drift []Drift
+	client.Drift = func(d Drift) {
+		drift = append(drift, d)
+	}
+
+	_, err := client.Bookings(BookingParams{ResourceID: 517})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drift) > 0 {
+		t.Errorf("unexpected drift: %v", drift)
+	}
+
+	_, err = client.Resources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// open_0 - close_6 are not modeled
+	if e := 14; len(drift) != e {
+		t.Fatalf("got: %d wanted: %d", len(drift), e)
+	}
+	if e := "resource.close_0: unknown field \"20:00\""; drift[0].String() != e {
+		t.Errorf("got: %s wanted: %s", drift[0], e)
+	}
+}
+
+func TestDrift_check(t *testing.T) {
+	drift := CheckDrift([]byte(`{"booking":{"id":"abc","count":1,"person":{"id":1,"nickname":"joe"}}}`))
+	if e := 2; len(drift) != e {
+		t.Fatalf("got: %d wanted: %d %v", len(drift), e, drift)
+	}
+
+	if e := DriftType; drift[0].Kind != e || drift[0].Field != "id" {
+		t.Errorf("got: %s wanted: %s on id", drift[0], e)
+	}
+
+	if e := "booking.person"; drift[1].Model != e || drift[1].Kind != DriftUnknown {
+		t.Errorf("got: %s wanted unknown field in %s", drift[1], e)
+	}
+
+	if drift := CheckDrift([]byte(`{"error":{"description":"Not found"}}`)); len(drift) > 0 {
+		t.Errorf("unexpected drift: %v", drift)
+	}
+}
@@ -0,0 +1,214 @@
+package makeplans
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Clock is a time of day expressed in minutes since midnight. Makeplans
+// formats it as "15:04", "24:00" is allowed as a closing time.
+type Clock int
+
+// ParseClock reads a "15:04" formatted time of day
+func ParseClock(s string) (Clock, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 2 {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	h, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	m, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	c := Clock(h*60 + m)
+	if h < 0 || m < 0 || m > 59 || c > 24*60 {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return c, nil
+}
+
+// ClockOf returns the time of day of t in t's location
+func ClockOf(t time.Time) Clock {
+	return Clock(t.Hour()*60 + t.Minute())
+}
+
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", int(c)/60, int(c)%60)
+}
+
+// On returns the time on the day of t at which the clock reads c
+func (c Clock) On(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, int(c), 0, 0, t.Location())
+}
+
+// TimeRange is a single opening period within a day. Open is inclusive,
+// Close is exclusive.
+type TimeRange struct {
+	Open  Clock
+	Close Clock
+}
+
+func (tr TimeRange) String() string {
+	return tr.Open.String() + "-" + tr.Close.String()
+}
+
+// Contains reports if the time of day falls within the range
+func (tr TimeRange) Contains(c Clock) bool {
+	return c >= tr.Open && c < tr.Close
+}
+
+// OpeningHours is the weekly schedule of a resource. Days missing from the
+// map are closed.
+type OpeningHours map[time.Weekday][]TimeRange
+
+// ParseTimeRanges converts the Makeplans representation of a day,
+// ["08:00", "11:00", "13:00", "17:30"], into ranges. The result is
+// validated.
+func ParseTimeRanges(hours []string) ([]TimeRange, error) {
+	if len(hours)%2 != 0 {
+		return nil, fmt.Errorf("opening hours must be pairs, got %d values",
+			len(hours))
+	}
+	ranges := make([]TimeRange, 0, len(hours)/2)
+	for i := 0; i < len(hours); i += 2 {
+		open, err := ParseClock(hours[i])
+		if err != nil {
+			return nil, err
+		}
+		close, err := ParseClock(hours[i+1])
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, TimeRange{Open: open, Close: close})
+	}
+	return ranges, validateRanges(ranges)
+}
+
+// FormatTimeRanges converts ranges into the Makeplans representation of
+// a day. nil is returned for a closed day.
+func FormatTimeRanges(ranges []TimeRange) []string {
+	if len(ranges) == 0 {
+		return nil
+	}
+	hours := make([]string, 0, len(ranges)*2)
+	for _, tr := range ranges {
+		hours = append(hours, tr.Open.String(), tr.Close.String())
+	}
+	return hours
+}
+
+func validateRanges(ranges []TimeRange) error {
+	for i, tr := range ranges {
+		if tr.Open >= tr.Close {
+			return fmt.Errorf("opening hours %s close before they open", tr)
+		}
+		if i > 0 && ranges[i-1].Close > tr.Open {
+			return fmt.Errorf("opening hours %s overlap or are out of order with %s",
+				tr, ranges[i-1])
+		}
+	}
+	return nil
+}
+
+// Validate checks every day is ordered, non-overlapping and that each
+// range opens before it closes.
+func (oh OpeningHours) Validate() error {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if err := validateRanges(oh[day]); err != nil {
+			return fmt.Errorf("%s: %s", day, err)
+		}
+	}
+	return nil
+}
+
+// Add opens the day for an additional range. Ranges are kept sorted, but
+// overlaps are left for Validate to report.
+func (oh OpeningHours) Add(day time.Weekday, tr TimeRange) {
+	ranges := append(oh[day], tr)
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Open < ranges[j].Open
+	})
+	oh[day] = ranges
+}
+
+// IsOpen reports if the schedule is open at t. The weekday and time of day
+// are read in t's location, which should be the account time zone.
+func (oh OpeningHours) IsOpen(t time.Time) bool {
+	c := ClockOf(t)
+	for _, tr := range oh[t.Weekday()] {
+		if tr.Contains(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Resource) openingHoursDay(day time.Weekday) *[]string {
+	switch day {
+	case time.Monday:
+		return &r.OpeningHoursMon
+	case time.Tuesday:
+		return &r.OpeningHoursTue
+	case time.Wednesday:
+		return &r.OpeningHoursWed
+	case time.Thursday:
+		return &r.OpeningHoursThu
+	case time.Friday:
+		return &r.OpeningHoursFri
+	case time.Saturday:
+		return &r.OpeningHoursSat
+	default:
+		return &r.OpeningHoursSun
+	}
+}
+
+// OpeningHours parses the weekly schedule of the resource
+func (r Resource) OpeningHours() (OpeningHours, error) {
+	oh := OpeningHours{}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		hours := *r.openingHoursDay(day)
+		if len(hours) == 0 {
+			continue
+		}
+		ranges, err := ParseTimeRanges(hours)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", day, err)
+		}
+		oh[day] = ranges
+	}
+	return oh, nil
+}
+
+// SetOpeningHours validates the schedule and replaces the opening hours of
+// the resource with it. Call ResourceUpdate to save the change. Closed days
+// are set to an empty list, which is sent as null to close them.
+func (r *Resource) SetOpeningHours(oh OpeningHours) error {
+	if err := oh.Validate(); err != nil {
+		return err
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		hours := FormatTimeRanges(oh[day])
+		if hours == nil {
+			hours = []string{}
+		}
+		*r.openingHoursDay(day) = hours
+	}
+	return nil
+}
+
+// IsOpen reports if the resource is open at t according to its weekly
+// schedule. Exception dates are not considered.
+func (r Resource) IsOpen(t time.Time) (bool, error) {
+	oh, err := r.OpeningHours()
+	if err != nil {
+		return false, err
+	}
+	return oh.IsOpen(t), nil
+}
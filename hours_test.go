@@ -0,0 +1,83 @@
+package makeplans
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOpeningHours_parse(t *testing.T) {
+	ranges, err := ParseTimeRanges([]string{"08:00", "11:00", "13:00", "17:30"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e := 2; len(ranges) != e {
+		t.Fatalf("got: %d wanted: %d", len(ranges), e)
+	}
+
+	if e := (TimeRange{Open: 13 * 60, Close: 17*60 + 30}); ranges[1] != e {
+		t.Errorf("got: %s wanted: %s", ranges[1], e)
+	}
+
+	invalid := [][]string{
+		{"08:00"},
+		{"08:00", "8:00"},
+		{"11:00", "08:00"},
+		{"08:00", "12:00", "11:00", "14:00"},
+		{"13:00", "14:00", "08:00", "12:00"},
+		{"08:00", "24:30"},
+	}
+	for _, hours := range invalid {
+		if _, err := ParseTimeRanges(hours); err == nil {
+			t.Errorf("expected error for %v", hours)
+		}
+	}
+}
+
+func TestOpeningHours_resource(t *testing.T) {
+	res := Resource{
+		OpeningHoursMon: []string{"08:00", "16:00"},
+		OpeningHoursTue: []string{"08:00", "11:00", "13:00", "17:30"},
+	}
+	oh, err := res.OpeningHours()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e := 2; len(oh) != e {
+		t.Fatalf("got: %d wanted: %d", len(oh), e)
+	}
+
+	// Tuesday December 15, 2015
+	lunch := time.Date(2015, 12, 15, 12, 0, 0, 0, time.UTC)
+	if oh.IsOpen(lunch) {
+		t.Errorf("expected closed at %s", lunch)
+	}
+	if e := true; oh.IsOpen(lunch.Add(time.Hour)) != e {
+		t.Errorf("expected open at %s", lunch.Add(time.Hour))
+	}
+	if open, _ := res.IsOpen(lunch.Add(-24 * time.Hour)); !open {
+		t.Errorf("expected open at %s", lunch.Add(-24*time.Hour))
+	}
+	// Closing time is exclusive
+	if open, _ := res.IsOpen(time.Date(2015, 12, 14, 16, 0, 0, 0, time.UTC)); open {
+		t.Error("expected closed at closing time")
+	}
+
+	oh.Add(time.Saturday, TimeRange{Open: 12 * 60, Close: 14 * 60})
+	oh.Add(time.Saturday, TimeRange{Open: 9 * 60, Close: 11 * 60})
+	err = res.SetOpeningHours(oh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e := []string{"09:00", "11:00", "12:00", "14:00"}; !reflect.DeepEqual(res.OpeningHoursSat, e) {
+		t.Errorf("got: %v wanted: %v", res.OpeningHoursSat, e)
+	}
+
+	oh.Add(time.Saturday, TimeRange{Open: 10 * 60, Close: 13 * 60})
+	if err := res.SetOpeningHours(oh); err == nil {
+		t.Error("expected overlap error")
+	}
+}
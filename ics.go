@@ -0,0 +1,344 @@
+package makeplans
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Calendar is a set of bookings and events written by WriteICS
+type Calendar struct {
+	// Name is shown by calendar apps
+	Name string
+	// Domain makes the UIDs unique, ie. the account host. Defaults to
+	// makeplans.net.
+	Domain string
+	// Location is the time zone of the calendar, defaults to UTC.
+	// time.Local has no zone name for calendar apps and is written as UTC.
+	Location *time.Location
+
+	Bookings []Booking
+	Events   []Event
+	// Services and Resources name the services and resources of bookings
+	// and events that don't embed them
+	Services  []Service
+	Resources []Resource
+}
+
+// icsStatus maps booking states to the STATUS of a VEVENT
+var icsStatus = map[BookingState]string{
+	BookingConfirmed:            "CONFIRMED",
+	BookingAwaitingConfirmation: "TENTATIVE",
+	BookingUnverified:           "TENTATIVE",
+	BookingDeclined:             "CANCELLED",
+	BookingCancelled:            "CANCELLED",
+	BookingExpired:              "CANCELLED",
+	BookingDeleted:              "CANCELLED",
+}
+
+// WriteICS writes cal as an iCalendar (RFC 5545) file with a VEVENT for
+// every booking and event that has a start. UIDs are derived from the IDs,
+// so calendar apps update events when the file is imported again.
+// Cancelled and declined bookings are kept with STATUS:CANCELLED to remove
+// them from calendars that saw them before.
+func WriteICS(w io.Writer, cal Calendar) error {
+	loc := cal.Location
+	if loc == nil || loc.String() == "Local" {
+		loc = time.UTC
+	}
+	domain := cal.Domain
+	if len(domain) == 0 {
+		domain = "makeplans.net"
+	}
+	services := map[int]string{}
+	for _, svc := range cal.Services {
+		services[svc.ID] = svc.Title
+	}
+	resources := map[int]string{}
+	for _, r := range cal.Resources {
+		resources[r.ID] = r.Title
+	}
+
+	iw := &icsWriter{w: bufio.NewWriter(w), loc: loc}
+	iw.line("BEGIN:VCALENDAR")
+	iw.line("VERSION:2.0")
+	iw.line("PRODID:-//drewwells//makeplans//EN")
+	iw.line("CALSCALE:GREGORIAN")
+	iw.line("METHOD:PUBLISH")
+	if len(cal.Name) > 0 {
+		iw.prop("X-WR-CALNAME", "", escapeText(cal.Name))
+	}
+	if !iw.utc() {
+		iw.prop("X-WR-TIMEZONE", "", loc.String())
+		iw.timezone(cal.span())
+	}
+
+	for _, b := range cal.Bookings {
+		if b.BookedFrom == nil {
+			continue
+		}
+		iw.line("BEGIN:VEVENT")
+		iw.prop("UID", "", fmt.Sprintf("booking-%d@%s", b.ID, domain))
+		iw.stamps(b.CreatedAt, b.UpdatedAt)
+		iw.time("DTSTART", *b.BookedFrom)
+		if b.BookedTo != nil && b.BookedTo.After(*b.BookedFrom) {
+			iw.time("DTEND", *b.BookedTo)
+		}
+
+		summary := services[b.ServiceID]
+		if b.Service != nil && len(b.Service.Title) > 0 {
+			summary = b.Service.Title
+		}
+		if len(summary) == 0 {
+			summary = fmt.Sprintf("Booking %d", b.ID)
+		}
+		if b.Person != nil && len(b.Person.Name) > 0 {
+			summary += " - " + b.Person.Name
+		}
+		iw.prop("SUMMARY", "", escapeText(summary))
+
+		location := resources[b.ResourceID]
+		if b.Resource != nil && len(b.Resource.Title) > 0 {
+			location = b.Resource.Title
+		}
+		if len(location) > 0 {
+			iw.prop("LOCATION", "", escapeText(location))
+		}
+
+		var desc []string
+		if b.Count > 1 {
+			desc = append(desc, fmt.Sprintf("Count: %d", b.Count))
+		}
+		if b.Person != nil && len(b.Person.PhoneNumber) > 0 {
+			desc = append(desc, "Phone: "+b.Person.PhoneNumber)
+		}
+		if len(b.Notes) > 0 {
+			desc = append(desc, b.Notes)
+		}
+		if len(desc) > 0 {
+			iw.prop("DESCRIPTION", "", escapeText(strings.Join(desc, "\n")))
+		}
+		if status, ok := icsStatus[b.State]; ok {
+			iw.prop("STATUS", "", status)
+		}
+		if b.Person != nil && len(b.Person.Email) > 0 {
+			params := ""
+			if len(b.Person.Name) > 0 {
+				params = ";CN=" + paramValue(b.Person.Name)
+			}
+			iw.prop("ATTENDEE", params, "mailto:"+b.Person.Email)
+		}
+		iw.line("END:VEVENT")
+	}
+
+	for _, e := range cal.Events {
+		if e.Start == nil {
+			continue
+		}
+		iw.line("BEGIN:VEVENT")
+		iw.prop("UID", "", fmt.Sprintf("event-%d@%s", e.ID, domain))
+		iw.stamps(e.CreatedAt, e.UpdatedAt)
+		iw.time("DTSTART", *e.Start)
+		if e.End != nil && e.End.After(*e.Start) {
+			iw.time("DTEND", *e.End)
+		}
+		summary := e.Title
+		if len(summary) == 0 {
+			summary = services[e.ServiceID]
+		}
+		if len(summary) == 0 {
+			summary = fmt.Sprintf("Event %d", e.ID)
+		}
+		iw.prop("SUMMARY", "", escapeText(summary))
+		if location := resources[e.ResourceID]; len(location) > 0 {
+			iw.prop("LOCATION", "", escapeText(location))
+		}
+		if len(e.Description) > 0 {
+			iw.prop("DESCRIPTION", "", escapeText(e.Description))
+		}
+		iw.line("END:VEVENT")
+	}
+
+	iw.line("END:VCALENDAR")
+	if iw.err != nil {
+		return iw.err
+	}
+	return iw.w.Flush()
+}
+
+// span is the period covered by the calendar
+func (cal Calendar) span() (from time.Time, to time.Time) {
+	add := func(t *time.Time) {
+		if t == nil {
+			return
+		}
+		if from.IsZero() || t.Before(from) {
+			from = *t
+		}
+		if t.After(to) {
+			to = *t
+		}
+	}
+	for _, b := range cal.Bookings {
+		add(b.BookedFrom)
+		add(b.BookedTo)
+	}
+	for _, e := range cal.Events {
+		add(e.Start)
+		add(e.End)
+	}
+	return
+}
+
+// icsWriter writes content lines, folded at 75 octets and ended by CRLF.
+// The first error is kept.
+type icsWriter struct {
+	w   *bufio.Writer
+	loc *time.Location
+	err error
+}
+
+func (iw *icsWriter) line(s string) {
+	if iw.err != nil {
+		return
+	}
+	var b strings.Builder
+	limit := 75
+	for len(s) > limit {
+		// Fold between characters
+		n := limit
+		for !utf8.RuneStart(s[n]) {
+			n--
+		}
+		b.WriteString(s[:n] + "\r\n ")
+		s = s[n:]
+		// The space starting a continuation line counts toward its length
+		limit = 74
+	}
+	b.WriteString(s + "\r\n")
+	_, iw.err = iw.w.WriteString(b.String())
+}
+
+// utc reports if times are written in UTC, without a VTIMEZONE
+func (iw *icsWriter) utc() bool {
+	return iw.loc.String() == "UTC"
+}
+
+func (iw *icsWriter) prop(name string, params string, value string) {
+	iw.line(name + params + ":" + value)
+}
+
+// time writes a date-time in the calendar time zone
+func (iw *icsWriter) time(name string, t time.Time) {
+	if iw.utc() {
+		iw.prop(name, "", t.UTC().Format("20060102T150405Z"))
+		return
+	}
+	iw.prop(name, ";TZID="+iw.loc.String(), t.In(iw.loc).Format("20060102T150405"))
+}
+
+// stamps writes DTSTAMP, CREATED and LAST-MODIFIED. DTSTAMP uses the last
+// change, keeping the output the same while nothing changes.
+func (iw *icsWriter) stamps(created *time.Time, updated *time.Time) {
+	stamp := updated
+	if stamp == nil {
+		stamp = created
+	}
+	if stamp == nil {
+		now := time.Now()
+		stamp = &now
+	}
+	iw.prop("DTSTAMP", "", stamp.UTC().Format("20060102T150405Z"))
+	if created != nil {
+		iw.prop("CREATED", "", created.UTC().Format("20060102T150405Z"))
+	}
+	if updated != nil {
+		iw.prop("LAST-MODIFIED", "", updated.UTC().Format("20060102T150405Z"))
+	}
+}
+
+// timezone writes a VTIMEZONE with the offsets of loc from a year before
+// from until a year after to. Every transition is listed, so the zone is
+// exact without recurrence rules.
+func (iw *icsWriter) timezone(from time.Time, to time.Time) {
+	if from.IsZero() {
+		from, to = time.Now(), time.Now()
+	}
+	start := from.AddDate(-1, 0, 0).In(iw.loc)
+	end := to.AddDate(1, 0, 0)
+
+	iw.line("BEGIN:VTIMEZONE")
+	iw.prop("TZID", "", iw.loc.String())
+	name, offset := start.Zone()
+	iw.observance(start, start.IsDST(), name, offset, offset)
+	for t := start; t.Before(end); {
+		next := t.Add(24 * time.Hour)
+		if _, o := next.Zone(); o == offset {
+			t = next
+			continue
+		}
+		// Narrow the change down to the second
+		lo, hi := t, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, o := mid.Zone(); o == offset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		var changed int
+		name, changed = hi.Zone()
+		iw.observance(hi, hi.IsDST(), name, offset, changed)
+		offset, t = changed, hi
+	}
+	iw.line("END:VTIMEZONE")
+}
+
+// observance writes a STANDARD or DAYLIGHT component starting at t, DTSTART
+// is local time before the change
+func (iw *icsWriter) observance(t time.Time, dst bool, name string, from int, to int) {
+	kind := "STANDARD"
+	if dst {
+		kind = "DAYLIGHT"
+	}
+	iw.line("BEGIN:" + kind)
+	iw.prop("DTSTART", "", t.UTC().Add(time.Duration(from)*time.Second).Format("20060102T150405"))
+	iw.prop("TZOFFSETFROM", "", utcOffset(from))
+	iw.prop("TZOFFSETTO", "", utcOffset(to))
+	if len(name) > 0 {
+		iw.prop("TZNAME", "", escapeText(name))
+	}
+	iw.line("END:" + kind)
+}
+
+func utcOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+	if seconds%60 > 0 {
+		s += fmt.Sprintf("%02d", seconds%60)
+	}
+	return s
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// paramValue quotes parameter values with separators, quotes can't be
+// escaped and are dropped
+func paramValue(s string) string {
+	s = strings.ReplaceAll(s, `"`, "")
+	if strings.ContainsAny(s, ";:,") {
+		return `"` + s + `"`
+	}
+	return s
+}
@@ -0,0 +1,138 @@
+package makeplans
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteICS(t *testing.T) {
+	from := time.Date(2015, 12, 14, 9, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	updated := time.Date(2015, 12, 1, 12, 0, 0, 0, time.UTC)
+	cal := Calendar{
+		Name:   "Gym",
+		Domain: "gym.makeplans.net",
+		Bookings: []Booking{
+			{
+				ID: 12, ServiceID: 393, ResourceID: 484, BookedFrom: &from, BookedTo: &to,
+				State: BookingConfirmed, Count: 2, Notes: "Bring a mat, please; thanks",
+				UpdatedAt: &updated,
+				Person:    &Person{Name: "Doe, Jane", Email: "jane@example.com"},
+			},
+			{ID: 13, ServiceID: 393, BookedFrom: &from, State: BookingCancelled,
+				Service: &Service{Title: "Morning yoga"}},
+			{ID: 14},
+		},
+		Events:    []Event{{ID: 3, ResourceID: 484, Start: &from, End: &to, UpdatedAt: &updated}},
+		Services:  []Service{{ID: 393, Title: "Yoga"}},
+		Resources: []Resource{{ID: 484, Title: "Studio"}},
+	}
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, cal); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	if !strings.Contains(got, "\r\n") || strings.Contains(strings.ReplaceAll(got, "\r\n", ""), "\n") {
+		t.Errorf("lines must end in CRLF")
+	}
+	lines := strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n")
+	e := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//drewwells//makeplans//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Gym",
+		"BEGIN:VEVENT",
+		"UID:booking-12@gym.makeplans.net",
+		"DTSTAMP:20151201T120000Z",
+		"LAST-MODIFIED:20151201T120000Z",
+		"DTSTART:20151214T090000Z",
+		"DTEND:20151214T100000Z",
+		`SUMMARY:Yoga - Doe\, Jane`,
+		"LOCATION:Studio",
+		`DESCRIPTION:Count: 2\nBring a mat\, please\; thanks`,
+		"STATUS:CONFIRMED",
+		`ATTENDEE;CN="Doe, Jane":mailto:jane@example.com`,
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:booking-13@gym.makeplans.net",
+	}
+	for i := range e {
+		if i >= len(lines) || lines[i] != e[i] {
+			t.Fatalf("line %d got:\n%s\nwanted: %s", i, got, e[i])
+		}
+	}
+	for _, e := range []string{
+		"SUMMARY:Morning yoga\r\n",
+		"STATUS:CANCELLED\r\n",
+		"UID:event-3@gym.makeplans.net\r\nDTSTAMP:20151201T120000Z\r\nLAST-MODIFIED:20151201T120000Z\r\nDTSTART:20151214T090000Z\r\nDTEND:20151214T100000Z\r\nSUMMARY:Event 3\r\nLOCATION:Studio\r\n",
+	} {
+		if !strings.Contains(got, e) {
+			t.Errorf("got:\n%s\nwanted: %q", got, e)
+		}
+	}
+	if strings.Contains(got, "booking-14") {
+		t.Errorf("booking without a time was written")
+	}
+}
+
+func TestWriteICS_fold(t *testing.T) {
+	var buf bytes.Buffer
+	iw := &icsWriter{w: bufio.NewWriter(&buf), loc: time.UTC}
+	iw.line("DESCRIPTION:" + strings.Repeat("å", 70))
+	iw.w.Flush()
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+	}
+	unfolded := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	if e := "DESCRIPTION:" + strings.Repeat("å", 70) + "\r\n"; unfolded != e {
+		t.Errorf("got: %q wanted: %q", unfolded, e)
+	}
+}
+
+func TestWriteICS_timezone(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skip(err)
+	}
+	from := time.Date(2015, 12, 14, 9, 0, 0, 0, loc)
+	var buf bytes.Buffer
+	err = WriteICS(&buf, Calendar{Location: loc, Bookings: []Booking{{ID: 1, BookedFrom: &from}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, e := range []string{
+		"X-WR-TIMEZONE:Europe/Oslo\r\nBEGIN:VTIMEZONE\r\nTZID:Europe/Oslo\r\n",
+		// Summer time of 2015 starts 02:00 local on March 29th
+		"BEGIN:DAYLIGHT\r\nDTSTART:20150329T020000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\nTZNAME:CEST\r\nEND:DAYLIGHT\r\n",
+		"BEGIN:STANDARD\r\nDTSTART:20151025T030000\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nTZNAME:CET\r\nEND:STANDARD\r\n",
+		"DTSTART;TZID=Europe/Oslo:20151214T090000\r\n",
+	} {
+		if !strings.Contains(got, e) {
+			t.Errorf("got:\n%s\nwanted: %q", got, e)
+		}
+	}
+}
+
+func TestWriteICS_local(t *testing.T) {
+	from := time.Date(2015, 12, 14, 9, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	err := WriteICS(&buf, Calendar{Location: time.Local, Bookings: []Booking{{ID: 1, BookedFrom: &from}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	if strings.Contains(got, "Local") {
+		t.Errorf("got:\n%s\nwanted no Local zone", got)
+	}
+	if e := "DTSTART:20151214T090000Z\r\n"; !strings.Contains(got, e) {
+		t.Errorf("got:\n%s\nwanted: %q", got, e)
+	}
+}
@@ -0,0 +1,531 @@
+package makeplans
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ImportRow is a record read from a CSV or JSON file, keyed by column
+type ImportRow struct {
+	// Line is the line of a CSV file or the index of a JSON object, both
+	// starting at 1
+	Line   int
+	Fields map[string]string
+}
+
+// ReadImportCSV reads a CSV file with a header row
+func ReadImportCSV(r io.Reader) ([]ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	rows := make([]ImportRow, 0, len(records)-1)
+	for i, rec := range records[1:] {
+		if len(rec) > len(header) {
+			return nil, fmt.Errorf("line %d: %d fields, header has %d",
+				i+2, len(rec), len(header))
+		}
+		row := ImportRow{Line: i + 2, Fields: map[string]string{}}
+		for j, v := range rec {
+			row.Fields[strings.TrimSpace(header[j])] = strings.TrimSpace(v)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ReadImportJSON reads an array of objects. Objects and arrays, ie.
+// custom_data, are kept as JSON text.
+func ReadImportJSON(r io.Reader) ([]ImportRow, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var objs []map[string]interface{}
+	if err := dec.Decode(&objs); err != nil {
+		return nil, err
+	}
+	rows := make([]ImportRow, len(objs))
+	for i, obj := range objs {
+		row := ImportRow{Line: i + 1, Fields: map[string]string{}}
+		for k, v := range obj {
+			switch t := v.(type) {
+			case nil:
+				row.Fields[k] = ""
+			case string:
+				row.Fields[k] = t
+			case json.Number, bool:
+				row.Fields[k] = fmt.Sprint(t)
+			default:
+				bs, err := json.Marshal(t)
+				if err != nil {
+					return nil, err
+				}
+				row.Fields[k] = string(bs)
+			}
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// ImportMapping maps columns of the file to fields. Fields are the json
+// names of the model, ie. "email", or "custom_data.<key>". Booking imports
+// also accept person_email and person_external_id to find the person.
+// Map a column to "-" to ignore it. Columns named after a field don't need
+// a mapping.
+type ImportMapping map[string]string
+
+// Import fields of bookings resolved to Booking.PersonID
+const (
+	ImportPersonEmail      = "person_email"
+	ImportPersonExternalID = "person_external_id"
+)
+
+// ImportAction is the outcome of a row
+type ImportAction string
+
+const (
+	ImportCreated ImportAction = "created"
+	ImportUpdated ImportAction = "updated"
+	// ImportSkipped is a booking whose external_id was imported before
+	ImportSkipped ImportAction = "skipped"
+	ImportFailed  ImportAction = "failed"
+)
+
+// ImportResult is the outcome of a single row. In a dry run nothing is
+// sent and created rows have no ID.
+type ImportResult struct {
+	Line   int          `json:"line"`
+	Action ImportAction `json:"action"`
+	ID     int          `json:"id,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// ImportAPI is the part of the API used by Importer
+type ImportAPI interface {
+	PersonService
+	BookingService
+}
+
+// Importer loads people and bookings. Rows are checked before anything is
+// sent, rows that fail don't stop the others.
+type Importer struct {
+	API     ImportAPI
+	Mapping ImportMapping
+	// Concurrency bounds the requests in flight, defaults to 4
+	Concurrency int
+	// DryRun checks and resolves every row without changing anything
+	DryRun bool
+	// Location is used for times without a zone, defaults to UTC
+	Location *time.Location
+}
+
+var (
+	personType  = reflect.TypeOf(Person{})
+	bookingType = reflect.TypeOf(Booking{})
+)
+
+// field returns the field a column maps to
+func (im *Importer) field(column string) string {
+	if f, ok := im.Mapping[column]; ok {
+		return f
+	}
+	return column
+}
+
+// checkColumns rejects columns that map to no field of t
+func (im *Importer) checkColumns(rows []ImportRow, t reflect.Type, extra ...string) error {
+	fields := jsonFields(t)
+	for _, e := range extra {
+		fields[e] = reflect.StructField{}
+	}
+	seen := map[string]bool{}
+	var unknown []string
+	for _, row := range rows {
+		for col := range row.Fields {
+			if seen[col] {
+				continue
+			}
+			seen[col] = true
+			f := im.field(col)
+			if _, ok := fields[f]; ok || f == "-" || strings.HasPrefix(f, "custom_data.") {
+				continue
+			}
+			unknown = append(unknown, col)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown columns %s, map them to a field or to -",
+			strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// apply sets the mapped fields of row on v, a pointer to a model. Empty
+// values are left alone. Columns in skip are returned instead of set.
+func (im *Importer) apply(row ImportRow, v interface{}, skip ...string) (map[string]string, error) {
+	rv := reflect.ValueOf(v).Elem()
+	fields := jsonFields(rv.Type())
+	rest := map[string]string{}
+	cols := make([]string, 0, len(row.Fields))
+	for col := range row.Fields {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+	for _, col := range cols {
+		s := row.Fields[col]
+		name := im.field(col)
+		if len(s) == 0 || name == "-" {
+			continue
+		}
+		if contains(skip, name) {
+			rest[name] = s
+			continue
+		}
+		if key := strings.TrimPrefix(name, "custom_data."); key != name {
+			cd := rv.FieldByName("CustomData").Addr().Interface().(*CustomData)
+			cd.Set(key, s)
+			continue
+		}
+		f := fields[name]
+		if err := im.set(rv.FieldByIndex(f.Index), s); err != nil {
+			return nil, fmt.Errorf("%s: %s", col, err)
+		}
+	}
+	return rest, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// importTimeLayouts are tried in order for times
+var importTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// set converts s to the type of v
+func (im *Importer) set(v reflect.Value, s string) error {
+	switch v.Interface().(type) {
+	case *time.Time:
+		loc := im.Location
+		if loc == nil {
+			loc = time.UTC
+		}
+		for _, layout := range importTimeLayouts {
+			if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+				v.Set(reflect.ValueOf(&t))
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid time %q", s)
+	case CustomData:
+		var cd CustomData
+		if err := json.Unmarshal([]byte(s), &cd); err != nil {
+			return fmt.Errorf("invalid custom data: %s", err)
+		}
+		v.Set(reflect.ValueOf(cd))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int:
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetInt(int64(i))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", s)
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("can't be imported")
+	}
+	return nil
+}
+
+// importJob is a checked row waiting to be sent
+type importJob struct {
+	i   int
+	run func() (ImportAction, int, error)
+}
+
+// run sends the jobs with bounded concurrency, filling in results
+func (im *Importer) run(results []ImportResult, jobs []importJob) {
+	n := im.Concurrency
+	if n < 1 {
+		n = 4
+	}
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(job importJob) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			action, id, err := job.run()
+			res := &results[job.i]
+			res.Action, res.ID = action, id
+			if err != nil {
+				res.Action, res.Error = ImportFailed, err.Error()
+			}
+		}(job)
+	}
+	wg.Wait()
+}
+
+// peopleIndex finds people by external id or lower cased email
+type peopleIndex struct {
+	external map[string]Person
+	email    map[string]Person
+}
+
+func (im *Importer) peopleIndex() (peopleIndex, error) {
+	idx := peopleIndex{external: map[string]Person{}, email: map[string]Person{}}
+	people, err := im.API.People()
+	if err != nil {
+		return idx, err
+	}
+	for _, p := range people {
+		if len(p.ExternalID) > 0 {
+			idx.external[p.ExternalID] = p
+		}
+		if len(p.Email) > 0 {
+			idx.email[strings.ToLower(p.Email)] = p
+		}
+	}
+	return idx, nil
+}
+
+func (idx peopleIndex) find(externalID string, email string) (Person, bool) {
+	if p, ok := idx.external[externalID]; ok && len(externalID) > 0 {
+		return p, true
+	}
+	p, ok := idx.email[strings.ToLower(email)]
+	return p, ok && len(email) > 0
+}
+
+// ImportPeople creates people, or updates them when a person with the same
+// external_id or email exists
+func (im *Importer) ImportPeople(rows []ImportRow) ([]ImportResult, error) {
+	if err := im.checkColumns(rows, personType); err != nil {
+		return nil, err
+	}
+	idx, err := im.peopleIndex()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ImportResult, len(rows))
+	var jobs []importJob
+	// keys remembers the first line of each person to catch duplicates
+	keys := map[string]int{}
+	for i, row := range rows {
+		results[i].Line = row.Line
+		fail := func(err error) {
+			results[i].Action, results[i].Error = ImportFailed, err.Error()
+		}
+
+		var p Person
+		if _, err := im.apply(row, &p); err != nil {
+			fail(err)
+			continue
+		}
+		dup := false
+		for _, key := range []string{"external_id:" + p.ExternalID, "email:" + strings.ToLower(p.Email)} {
+			if strings.HasSuffix(key, ":") {
+				continue
+			}
+			if line, ok := keys[key]; ok {
+				fail(fmt.Errorf("duplicate of line %d", line))
+				dup = true
+				break
+			}
+			keys[key] = row.Line
+		}
+		if dup {
+			continue
+		}
+
+		existing, ok := idx.find(p.ExternalID, p.Email)
+		if !ok {
+			// Updates keep the name of the existing person
+			if len(p.Name) == 0 {
+				fail(fmt.Errorf("name: can't be blank"))
+				continue
+			}
+			results[i].Action = ImportCreated
+			if !im.DryRun {
+				jobs = append(jobs, importJob{i, func() (ImportAction, int, error) {
+					created, err := im.API.MakePerson(p)
+					return ImportCreated, created.ID, err
+				}})
+			}
+			continue
+		}
+		// Apply the row over the existing person so unset columns are kept
+		if _, err := im.apply(row, &existing); err != nil {
+			fail(err)
+			continue
+		}
+		results[i].Action, results[i].ID = ImportUpdated, existing.ID
+		if !im.DryRun {
+			jobs = append(jobs, importJob{i, func() (ImportAction, int, error) {
+				updated, err := im.API.UpdatePerson(existing)
+				return ImportUpdated, updated.ID, err
+			}})
+		}
+	}
+	im.run(results, jobs)
+	return results, nil
+}
+
+// ImportBookings creates bookings. The person is found by person_id,
+// person_external_id or person_email. Bookings with an external_id already
+// in Makeplans, in any state, are skipped, so an import can be run again.
+func (im *Importer) ImportBookings(rows []ImportRow) ([]ImportResult, error) {
+	if err := im.checkColumns(rows, bookingType, ImportPersonEmail, ImportPersonExternalID); err != nil {
+		return nil, err
+	}
+	idx, err := im.peopleIndex()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ImportResult, len(rows))
+	var jobs []importJob
+	external := map[string]int{}
+	// imported holds the bookings in Makeplans by external_id
+	imported := map[string]int{}
+	for i, row := range rows {
+		results[i].Line = row.Line
+		fail := func(err error) {
+			results[i].Action, results[i].Error = ImportFailed, err.Error()
+		}
+
+		var b Booking
+		person, err := im.apply(row, &b, ImportPersonEmail, ImportPersonExternalID)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		if b.PersonID == 0 && len(person) > 0 {
+			p, ok := idx.find(person[ImportPersonExternalID], person[ImportPersonEmail])
+			if !ok {
+				fail(fmt.Errorf("person not found"))
+				continue
+			}
+			b.PersonID = p.ID
+		}
+		fe := FieldError{}
+		if b.ServiceID == 0 {
+			fe["service_id"] = []string{"can't be blank"}
+		}
+		if b.BookedFrom == nil {
+			fe["booked_from"] = []string{"can't be blank"}
+		}
+		if len(fe) > 0 {
+			fail(fe)
+			continue
+		}
+		if len(b.ExternalID) > 0 {
+			if line, ok := external[b.ExternalID]; ok {
+				fail(fmt.Errorf("duplicate of line %d", line))
+				continue
+			}
+			external[b.ExternalID] = row.Line
+		}
+
+		results[i].Action = ImportCreated
+		jobs = append(jobs, importJob{i, func() (ImportAction, int, error) {
+			if id, ok := imported[b.ExternalID]; ok && len(b.ExternalID) > 0 {
+				return ImportSkipped, id, nil
+			}
+			if im.DryRun {
+				return ImportCreated, 0, nil
+			}
+			created, err := im.API.MakeBooking(b)
+			return ImportCreated, created.ID, err
+		}})
+	}
+	if len(external) > 0 {
+		// Cancelled and expired bookings count as imported too
+		all, err := im.API.BookingAll()
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range all {
+			if _, ok := external[b.ExternalID]; ok {
+				imported[b.ExternalID] = b.ID
+			}
+		}
+	}
+	im.run(results, jobs)
+	return results, nil
+}
+
+// WriteImportReport writes the results as CSV with a header
+func WriteImportReport(w io.Writer, results []ImportResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"line", "action", "id", "error"})
+	for _, res := range results {
+		id := ""
+		if res.ID > 0 {
+			id = strconv.Itoa(res.ID)
+		}
+		cw.Write([]string{strconv.Itoa(res.Line), string(res.Action), id, res.Error})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ImportSummary counts the results by action, ie. "3 created, 1 failed"
+func ImportSummary(results []ImportResult) string {
+	counts := map[ImportAction]int{}
+	for _, res := range results {
+		counts[res.Action]++
+	}
+	var buf bytes.Buffer
+	for _, action := range []ImportAction{ImportCreated, ImportUpdated, ImportSkipped, ImportFailed} {
+		if counts[action] == 0 {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%d %s", counts[action], action)
+	}
+	if buf.Len() == 0 {
+		return "nothing imported"
+	}
+	return buf.String()
+}
@@ -0,0 +1,230 @@
+package makeplans_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drewwells/makeplans"
+	"github.com/drewwells/makeplans/makeplansmock"
+)
+
+// importMock keeps people and bookings in memory behind makeplansmock,
+// methods the importer isn't expected to call return ErrNotMocked
+type importMock struct {
+	*makeplansmock.Client
+	mu       sync.Mutex
+	people   []makeplans.Person
+	bookings []makeplans.Booking
+	// inFlight tracks concurrent requests
+	inFlight, maxInFlight int
+}
+
+func newImportMock(people []makeplans.Person, bookings []makeplans.Booking) *importMock {
+	f := &importMock{people: people, bookings: bookings}
+	f.Client = &makeplansmock.Client{
+		PeopleFunc: func() ([]makeplans.Person, error) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			return append([]makeplans.Person{}, f.people...), nil
+		},
+		MakePersonFunc: func(p makeplans.Person) (makeplans.Person, error) {
+			defer f.enter()()
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			p.ID = 100 + len(f.people)
+			f.people = append(f.people, p)
+			return p, nil
+		},
+		UpdatePersonFunc: func(p makeplans.Person) (makeplans.Person, error) {
+			defer f.enter()()
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			for i := range f.people {
+				if f.people[i].ID == p.ID {
+					f.people[i] = p
+					return p, nil
+				}
+			}
+			return makeplans.Person{}, makeplans.ErrNotFound
+		},
+		BookingAllFunc: func() ([]makeplans.Booking, error) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			return append([]makeplans.Booking{}, f.bookings...), nil
+		},
+		MakeBookingFunc: func(b makeplans.Booking) (makeplans.Booking, error) {
+			defer f.enter()()
+			if b.ServiceID == 13 {
+				return makeplans.Booking{}, errors.New("error service_id: is inactive")
+			}
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			b.ID = 500 + len(f.bookings)
+			f.bookings = append(f.bookings, b)
+			return b, nil
+		},
+	}
+	return f
+}
+
+func (f *importMock) enter() func() {
+	f.mu.Lock()
+	f.inFlight++
+	if f.inFlight > f.maxInFlight {
+		f.maxInFlight = f.inFlight
+	}
+	f.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	return func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}
+}
+
+func TestImport_people(t *testing.T) {
+	fake := newImportMock([]makeplans.Person{
+		{ID: 1, Name: "Jane", Email: "JANE@example.com", Notes: "vip"},
+		{ID: 2, Name: "Kim", Email: "kim@example.com"},
+	}, nil)
+	rows, err := makeplans.ReadImportCSV(strings.NewReader(`Full name,E-mail,phonenumber,level,old id
+Jane Doe,jane@example.com,555-0100,3,a1
+Bob,bob@example.com,,,a2
+,nobody@example.com,,,a3
+Bobby,BOB@example.com,,,a4
+,kim@example.com,555-0199,,a5
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	im := &makeplans.Importer{
+		API: fake,
+		Mapping: makeplans.ImportMapping{
+			"Full name": "name",
+			"E-mail":    "email",
+			"level":     "custom_data.level",
+			"old id":    "-",
+		},
+	}
+	results, err := im.ImportPeople(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := []makeplans.ImportResult{
+		{Line: 2, Action: makeplans.ImportUpdated, ID: 1},
+		{Line: 3, Action: makeplans.ImportCreated, ID: 102},
+		{Line: 4, Action: makeplans.ImportFailed, Error: "name: can't be blank"},
+		{Line: 5, Action: makeplans.ImportFailed, Error: "duplicate of line 3"},
+		// The name is only required for new people
+		{Line: 6, Action: makeplans.ImportUpdated, ID: 2},
+	}
+	for i := range e {
+		if results[i] != e[i] {
+			t.Errorf("%d got: %+v wanted: %+v", i, results[i], e[i])
+		}
+	}
+
+	jane := fake.people[0]
+	if jane.Name != "Jane Doe" || jane.PhoneNumber != "555-0100" || jane.Notes != "vip" {
+		t.Errorf("got: %+v", jane)
+	}
+	if level, _ := makeplans.CustomValue[int](jane.CustomData, "level"); level != 3 {
+		t.Errorf("got: %d wanted: %d", level, 3)
+	}
+	if kim := fake.people[1]; kim.Name != "Kim" || kim.PhoneNumber != "555-0199" {
+		t.Errorf("got: %+v", kim)
+	}
+
+	if e := "1 created, 2 updated, 2 failed"; makeplans.ImportSummary(results) != e {
+		t.Errorf("got: %s wanted: %s", makeplans.ImportSummary(results), e)
+	}
+
+	var buf bytes.Buffer
+	if err := makeplans.WriteImportReport(&buf, results[2:3]); err != nil {
+		t.Fatal(err)
+	}
+	if e := "line,action,id,error\n4,failed,,name: can't be blank\n"; buf.String() != e {
+		t.Errorf("got: %q wanted: %q", buf.String(), e)
+	}
+
+	_, err = (&makeplans.Importer{API: fake}).ImportPeople(rows)
+	if e := "unknown columns E-mail, Full name, level, old id, map them to a field or to -"; err == nil || err.Error() != e {
+		t.Errorf("got: %v wanted: %s", err, e)
+	}
+}
+
+func TestImport_bookings(t *testing.T) {
+	fake := newImportMock(
+		[]makeplans.Person{{ID: 1, Email: "jane@example.com", ExternalID: "p1"}},
+		[]makeplans.Booking{{ID: 9, ExternalID: "b0", State: makeplans.BookingCancelled}},
+	)
+	rows, err := makeplans.ReadImportJSON(strings.NewReader(`[
+		{"external_id": "b0", "service_id": 393, "booked_from": "2015-12-14 09:00"},
+		{"external_id": "b1", "service_id": 393, "booked_from": "2015-12-14 10:00", "person_email": "JANE@example.com", "count": 2},
+		{"external_id": "b2", "service_id": 393, "booked_from": "2015-12-14T11:00:00+01:00", "person_external_id": "p1", "custom_data": {"room": "a"}},
+		{"external_id": "b3", "service_id": 393, "booked_from": "2015-12-14", "person_email": "bob@example.com"},
+		{"external_id": "b4", "booked_from": "tomorrow"},
+		{"external_id": "b5", "service_id": 13, "booked_from": "2015-12-14"},
+		{"external_id": "b1", "service_id": 393, "booked_from": "2015-12-14"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc := time.FixedZone("CST", -6*60*60)
+	im := &makeplans.Importer{API: fake, DryRun: true, Location: loc, Concurrency: 2}
+	results, err := im.ImportBookings(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := []makeplans.ImportResult{
+		{Line: 1, Action: makeplans.ImportSkipped, ID: 9},
+		{Line: 2, Action: makeplans.ImportCreated},
+		{Line: 3, Action: makeplans.ImportCreated},
+		{Line: 4, Action: makeplans.ImportFailed, Error: "person not found"},
+		{Line: 5, Action: makeplans.ImportFailed, Error: "booked_from: invalid time \"tomorrow\""},
+		{Line: 6, Action: makeplans.ImportCreated},
+		{Line: 7, Action: makeplans.ImportFailed, Error: "duplicate of line 2"},
+	}
+	for i := range e {
+		if results[i] != e[i] {
+			t.Errorf("%d got: %+v wanted: %+v", i, results[i], e[i])
+		}
+	}
+	if e := 1; len(fake.bookings) != e {
+		t.Fatalf("dry run created bookings got: %d wanted: %d", len(fake.bookings), e)
+	}
+
+	im.DryRun = false
+	results, err = im.ImportBookings(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "2 created, 1 skipped, 4 failed"; makeplans.ImportSummary(results) != e {
+		t.Errorf("got: %s wanted: %s", makeplans.ImportSummary(results), e)
+	}
+	if e := "error service_id: is inactive"; results[5].Error != e {
+		t.Errorf("got: %s wanted: %s", results[5].Error, e)
+	}
+	if fake.maxInFlight > im.Concurrency {
+		t.Errorf("got: %d requests in flight, limit %d", fake.maxInFlight, im.Concurrency)
+	}
+
+	byExternal := map[string]makeplans.Booking{}
+	for _, b := range fake.bookings {
+		byExternal[b.ExternalID] = b
+	}
+	b := byExternal["b1"]
+	if b.PersonID != 1 || b.Count != 2 {
+		t.Errorf("got: %+v wanted person 1 count 2", b)
+	}
+	if e := time.Date(2015, 12, 14, 10, 0, 0, 0, loc); !b.BookedFrom.Equal(e) {
+		t.Errorf("got: %s wanted: %s", b.BookedFrom, e)
+	}
+	if e := "a"; byExternal["b2"].CustomData["room"] != e {
+		t.Errorf("got: %v wanted: %s", byExternal["b2"].CustomData, e)
+	}
+}
@@ -0,0 +1,80 @@
+package makeplans
+
+import "time"
+
+// BookingService is the booking API implemented by Client
+type BookingService interface {
+	Booking(bookingID int) (Booking, error)
+	Bookings(params BookingParams) ([]Booking, error)
+	BookingAll() ([]Booking, error)
+	MakeBooking(b Booking) (Booking, error)
+	BookingUpdate(b Booking) (Booking, error)
+	BookingDelete(id int) (Booking, error)
+	BookingCancel(id int) (Booking, error)
+	BookingVerify(id int) (Booking, error)
+	BookingConfirm(id int) (Booking, error)
+	BookingDecline(id int) (Booking, error)
+	TransitionBooking(id int, to BookingState) (Booking, error)
+}
+
+// PersonService is the people API implemented by Client
+type PersonService interface {
+	People() ([]Person, error)
+	MakePerson(p Person) (Person, error)
+	UpdatePerson(p Person) (Person, error)
+	DeletePerson(p Person) error
+}
+
+// SlotService is the availability API implemented by Client
+type SlotService interface {
+	ServiceSlot(serviceID int, params SlotParams) ([]Slot, error)
+	ResourceSlots(resourceID int, params SlotParams) ([]Slot, error)
+	NextAvailableDate(serviceID int, params SlotParams) (time.Time, error)
+	NextAvailableSlot(serviceID int, params SlotParams) (Slot, error)
+}
+
+// ResourceService is the resource API implemented by Client
+type ResourceService interface {
+	Resources() ([]Resource, error)
+	Resource(id int) (Resource, error)
+	ResourceOpening(id int, from time.Time, to time.Time) (Resource, error)
+	ResourceUpdate(r Resource) (Resource, error)
+	ResourceDelete(id int) (Resource, error)
+	MakeResource(r Resource) (Resource, error)
+}
+
+// ServiceService is the service API implemented by Client
+type ServiceService interface {
+	Services() ([]Service, error)
+	ServiceSave(svc Service) (Service, error)
+	ServiceCreate(new Service) (Service, error)
+	ServiceDelete(id int) (Service, error)
+}
+
+// ProviderService is the provider API implemented by Client
+type ProviderService interface {
+	Providers() ([]Provider, error)
+	MakeProvider(in Provider) (Provider, error)
+	ProviderUpdate(in Provider) (Provider, error)
+	ProviderDelete(id int) (Provider, error)
+	SyncProviders(resourceID int, serviceIDs []int) (created []Provider, deleted []Provider, err error)
+}
+
+// EventService is the event API implemented by Client
+type EventService interface {
+	Events() ([]Event, error)
+}
+
+// API is the complete Makeplans API. Depend on it, or the smaller
+// interfaces, instead of *Client to substitute a mock in tests.
+type API interface {
+	BookingService
+	PersonService
+	SlotService
+	ResourceService
+	ServiceService
+	ProviderService
+	EventService
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,412 @@
+// Package makeplansmock provides a mock of makeplans.API for unit tests.
+// Set the Func field of every method the code under test calls, then
+// inspect Calls to assert on the arguments.
+//
+//	m := &makeplansmock.Client{
+//		PeopleFunc: func() ([]makeplans.Person, error) {
+//			return []makeplans.Person{{ID: 1}}, nil
+//		},
+//	}
+//	doWork(m)
+//	if len(m.CallsTo("People")) != 1 { ... }
+package makeplansmock
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/drewwells/makeplans"
+)
+
+// ErrNotMocked is returned by methods without a Func set
+var ErrNotMocked = errors.New("makeplansmock: method not mocked")
+
+// Call is a single recorded method call
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Client implements makeplans.API by calling the matching Func field
+type Client struct {
+	BookingFunc           func(bookingID int) (makeplans.Booking, error)
+	BookingsFunc          func(params makeplans.BookingParams) ([]makeplans.Booking, error)
+	BookingAllFunc        func() ([]makeplans.Booking, error)
+	MakeBookingFunc       func(b makeplans.Booking) (makeplans.Booking, error)
+	BookingUpdateFunc     func(b makeplans.Booking) (makeplans.Booking, error)
+	BookingDeleteFunc     func(id int) (makeplans.Booking, error)
+	BookingCancelFunc     func(id int) (makeplans.Booking, error)
+	BookingVerifyFunc     func(id int) (makeplans.Booking, error)
+	BookingConfirmFunc    func(id int) (makeplans.Booking, error)
+	BookingDeclineFunc    func(id int) (makeplans.Booking, error)
+	TransitionBookingFunc func(id int, to makeplans.BookingState) (makeplans.Booking, error)
+	PeopleFunc            func() ([]makeplans.Person, error)
+	MakePersonFunc        func(p makeplans.Person) (makeplans.Person, error)
+	UpdatePersonFunc      func(p makeplans.Person) (makeplans.Person, error)
+	DeletePersonFunc      func(p makeplans.Person) error
+	ServiceSlotFunc       func(serviceID int, params makeplans.SlotParams) ([]makeplans.Slot, error)
+	ResourceSlotsFunc     func(resourceID int, params makeplans.SlotParams) ([]makeplans.Slot, error)
+	NextAvailableDateFunc func(serviceID int, params makeplans.SlotParams) (time.Time, error)
+	NextAvailableSlotFunc func(serviceID int, params makeplans.SlotParams) (makeplans.Slot, error)
+	ResourcesFunc         func() ([]makeplans.Resource, error)
+	ResourceFunc          func(id int) (makeplans.Resource, error)
+	ResourceOpeningFunc   func(id int, from time.Time, to time.Time) (makeplans.Resource, error)
+	ResourceUpdateFunc    func(r makeplans.Resource) (makeplans.Resource, error)
+	ResourceDeleteFunc    func(id int) (makeplans.Resource, error)
+	MakeResourceFunc      func(r makeplans.Resource) (makeplans.Resource, error)
+	ServicesFunc          func() ([]makeplans.Service, error)
+	ServiceSaveFunc       func(svc makeplans.Service) (makeplans.Service, error)
+	ServiceCreateFunc     func(new makeplans.Service) (makeplans.Service, error)
+	ServiceDeleteFunc     func(id int) (makeplans.Service, error)
+	ProvidersFunc         func() ([]makeplans.Provider, error)
+	MakeProviderFunc      func(in makeplans.Provider) (makeplans.Provider, error)
+	ProviderUpdateFunc    func(in makeplans.Provider) (makeplans.Provider, error)
+	ProviderDeleteFunc    func(id int) (makeplans.Provider, error)
+	SyncProvidersFunc     func(resourceID int, serviceIDs []int) (created []makeplans.Provider, deleted []makeplans.Provider, err error)
+	EventsFunc            func() ([]makeplans.Event, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ makeplans.API = (*Client)(nil)
+
+func (m *Client) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns every call made in order
+func (m *Client) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the calls made to a single method
+func (m *Client) CallsTo(method string) []Call {
+	var calls []Call
+	for _, c := range m.Calls() {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Booking records the call and calls BookingFunc
+func (m *Client) Booking(bookingID int) (makeplans.Booking, error) {
+	m.record("Booking", bookingID)
+	if m.BookingFunc == nil {
+		return makeplans.Booking{}, ErrNotMocked
+	}
+	return m.BookingFunc(bookingID)
+}
+
+// Bookings records the call and calls BookingsFunc
+func (m *Client) Bookings(params makeplans.BookingParams) ([]makeplans.Booking, error) {
+	m.record("Bookings", params)
+	if m.BookingsFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.BookingsFunc(params)
+}
+
+// BookingAll records the call and calls BookingAllFunc
+func (m *Client) BookingAll() ([]makeplans.Booking, error) {
+	m.record("BookingAll")
+	if m.BookingAllFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.BookingAllFunc()
+}
+
+// MakeBooking records the call and calls MakeBookingFunc
+func (m *Client) MakeBooking(b makeplans.Booking) (makeplans.Booking, error) {
+	m.record("MakeBooking", b)
+	if m.MakeBookingFunc == nil {
+		return makeplans.Booking{}, ErrNotMocked
+	}
+	return m.MakeBookingFunc(b)
+}
+
+// BookingUpdate records the call and calls BookingUpdateFunc
+func (m *Client) BookingUpdate(b makeplans.Booking) (makeplans.Booking, error) {
+	m.record("BookingUpdate", b)
+	if m.BookingUpdateFunc == nil {
+		return makeplans.Booking{}, ErrNotMocked
+	}
+	return m.BookingUpdateFunc(b)
+}
+
+// BookingDelete records the call and calls BookingDeleteFunc
+func (m *Client) BookingDelete(id int) (makeplans.Booking, error) {
+	m.record("BookingDelete", id)
+	if m.BookingDeleteFunc == nil {
+		return makeplans.Booking{}, ErrNotMocked
+	}
+	return m.BookingDeleteFunc(id)
+}
+
+// BookingCancel records the call and calls BookingCancelFunc
+func (m *Client) BookingCancel(id int) (makeplans.Booking, error) {
+	m.record("BookingCancel", id)
+	if m.BookingCancelFunc == nil {
+		return makeplans.Booking{}, ErrNotMocked
+	}
+	return m.BookingCancelFunc(id)
+}
+
+// BookingVerify records the call and calls BookingVerifyFunc
+func (m *Client) BookingVerify(id int) (makeplans.Booking, error) {
+	m.record("BookingVerify", id)
+	if m.BookingVerifyFunc == nil {
+		return makeplans.Booking{}, ErrNotMocked
+	}
+	return m.BookingVerifyFunc(id)
+}
+
+// BookingConfirm records the call and calls BookingConfirmFunc
+func (m *Client) BookingConfirm(id int) (makeplans.Booking, error) {
+	m.record("BookingConfirm", id)
+	if m.BookingConfirmFunc == nil {
+		return makeplans.Booking{}, ErrNotMocked
+	}
+	return m.BookingConfirmFunc(id)
+}
+
+// BookingDecline records the call and calls BookingDeclineFunc
+func (m *Client) BookingDecline(id int) (makeplans.Booking, error) {
+	m.record("BookingDecline", id)
+	if m.BookingDeclineFunc == nil {
+		return makeplans.Booking{}, ErrNotMocked
+	}
+	return m.BookingDeclineFunc(id)
+}
+
+// TransitionBooking records the call and calls TransitionBookingFunc
+func (m *Client) TransitionBooking(id int, to makeplans.BookingState) (makeplans.Booking, error) {
+	m.record("TransitionBooking", id, to)
+	if m.TransitionBookingFunc == nil {
+		return makeplans.Booking{}, ErrNotMocked
+	}
+	return m.TransitionBookingFunc(id, to)
+}
+
+// People records the call and calls PeopleFunc
+func (m *Client) People() ([]makeplans.Person, error) {
+	m.record("People")
+	if m.PeopleFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.PeopleFunc()
+}
+
+// MakePerson records the call and calls MakePersonFunc
+func (m *Client) MakePerson(p makeplans.Person) (makeplans.Person, error) {
+	m.record("MakePerson", p)
+	if m.MakePersonFunc == nil {
+		return makeplans.Person{}, ErrNotMocked
+	}
+	return m.MakePersonFunc(p)
+}
+
+// UpdatePerson records the call and calls UpdatePersonFunc
+func (m *Client) UpdatePerson(p makeplans.Person) (makeplans.Person, error) {
+	m.record("UpdatePerson", p)
+	if m.UpdatePersonFunc == nil {
+		return makeplans.Person{}, ErrNotMocked
+	}
+	return m.UpdatePersonFunc(p)
+}
+
+// DeletePerson records the call and calls DeletePersonFunc
+func (m *Client) DeletePerson(p makeplans.Person) error {
+	m.record("DeletePerson", p)
+	if m.DeletePersonFunc == nil {
+		return ErrNotMocked
+	}
+	return m.DeletePersonFunc(p)
+}
+
+// ServiceSlot records the call and calls ServiceSlotFunc
+func (m *Client) ServiceSlot(serviceID int, params makeplans.SlotParams) ([]makeplans.Slot, error) {
+	m.record("ServiceSlot", serviceID, params)
+	if m.ServiceSlotFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.ServiceSlotFunc(serviceID, params)
+}
+
+// ResourceSlots records the call and calls ResourceSlotsFunc
+func (m *Client) ResourceSlots(resourceID int, params makeplans.SlotParams) ([]makeplans.Slot, error) {
+	m.record("ResourceSlots", resourceID, params)
+	if m.ResourceSlotsFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.ResourceSlotsFunc(resourceID, params)
+}
+
+// NextAvailableDate records the call and calls NextAvailableDateFunc
+func (m *Client) NextAvailableDate(serviceID int, params makeplans.SlotParams) (time.Time, error) {
+	m.record("NextAvailableDate", serviceID, params)
+	if m.NextAvailableDateFunc == nil {
+		return time.Time{}, ErrNotMocked
+	}
+	return m.NextAvailableDateFunc(serviceID, params)
+}
+
+// NextAvailableSlot records the call and calls NextAvailableSlotFunc
+func (m *Client) NextAvailableSlot(serviceID int, params makeplans.SlotParams) (makeplans.Slot, error) {
+	m.record("NextAvailableSlot", serviceID, params)
+	if m.NextAvailableSlotFunc == nil {
+		return makeplans.Slot{}, ErrNotMocked
+	}
+	return m.NextAvailableSlotFunc(serviceID, params)
+}
+
+// Resources records the call and calls ResourcesFunc
+func (m *Client) Resources() ([]makeplans.Resource, error) {
+	m.record("Resources")
+	if m.ResourcesFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.ResourcesFunc()
+}
+
+// Resource records the call and calls ResourceFunc
+func (m *Client) Resource(id int) (makeplans.Resource, error) {
+	m.record("Resource", id)
+	if m.ResourceFunc == nil {
+		return makeplans.Resource{}, ErrNotMocked
+	}
+	return m.ResourceFunc(id)
+}
+
+// ResourceOpening records the call and calls ResourceOpeningFunc
+func (m *Client) ResourceOpening(id int, from time.Time, to time.Time) (makeplans.Resource, error) {
+	m.record("ResourceOpening", id, from, to)
+	if m.ResourceOpeningFunc == nil {
+		return makeplans.Resource{}, ErrNotMocked
+	}
+	return m.ResourceOpeningFunc(id, from, to)
+}
+
+// ResourceUpdate records the call and calls ResourceUpdateFunc
+func (m *Client) ResourceUpdate(r makeplans.Resource) (makeplans.Resource, error) {
+	m.record("ResourceUpdate", r)
+	if m.ResourceUpdateFunc == nil {
+		return makeplans.Resource{}, ErrNotMocked
+	}
+	return m.ResourceUpdateFunc(r)
+}
+
+// ResourceDelete records the call and calls ResourceDeleteFunc
+func (m *Client) ResourceDelete(id int) (makeplans.Resource, error) {
+	m.record("ResourceDelete", id)
+	if m.ResourceDeleteFunc == nil {
+		return makeplans.Resource{}, ErrNotMocked
+	}
+	return m.ResourceDeleteFunc(id)
+}
+
+// MakeResource records the call and calls MakeResourceFunc
+func (m *Client) MakeResource(r makeplans.Resource) (makeplans.Resource, error) {
+	m.record("MakeResource", r)
+	if m.MakeResourceFunc == nil {
+		return makeplans.Resource{}, ErrNotMocked
+	}
+	return m.MakeResourceFunc(r)
+}
+
+// Services records the call and calls ServicesFunc
+func (m *Client) Services() ([]makeplans.Service, error) {
+	m.record("Services")
+	if m.ServicesFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.ServicesFunc()
+}
+
+// ServiceSave records the call and calls ServiceSaveFunc
+func (m *Client) ServiceSave(svc makeplans.Service) (makeplans.Service, error) {
+	m.record("ServiceSave", svc)
+	if m.ServiceSaveFunc == nil {
+		return makeplans.Service{}, ErrNotMocked
+	}
+	return m.ServiceSaveFunc(svc)
+}
+
+// ServiceCreate records the call and calls ServiceCreateFunc
+func (m *Client) ServiceCreate(new makeplans.Service) (makeplans.Service, error) {
+	m.record("ServiceCreate", new)
+	if m.ServiceCreateFunc == nil {
+		return makeplans.Service{}, ErrNotMocked
+	}
+	return m.ServiceCreateFunc(new)
+}
+
+// ServiceDelete records the call and calls ServiceDeleteFunc
+func (m *Client) ServiceDelete(id int) (makeplans.Service, error) {
+	m.record("ServiceDelete", id)
+	if m.ServiceDeleteFunc == nil {
+		return makeplans.Service{}, ErrNotMocked
+	}
+	return m.ServiceDeleteFunc(id)
+}
+
+// Providers records the call and calls ProvidersFunc
+func (m *Client) Providers() ([]makeplans.Provider, error) {
+	m.record("Providers")
+	if m.ProvidersFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.ProvidersFunc()
+}
+
+// MakeProvider records the call and calls MakeProviderFunc
+func (m *Client) MakeProvider(in makeplans.Provider) (makeplans.Provider, error) {
+	m.record("MakeProvider", in)
+	if m.MakeProviderFunc == nil {
+		return makeplans.Provider{}, ErrNotMocked
+	}
+	return m.MakeProviderFunc(in)
+}
+
+// ProviderUpdate records the call and calls ProviderUpdateFunc
+func (m *Client) ProviderUpdate(in makeplans.Provider) (makeplans.Provider, error) {
+	m.record("ProviderUpdate", in)
+	if m.ProviderUpdateFunc == nil {
+		return makeplans.Provider{}, ErrNotMocked
+	}
+	return m.ProviderUpdateFunc(in)
+}
+
+// ProviderDelete records the call and calls ProviderDeleteFunc
+func (m *Client) ProviderDelete(id int) (makeplans.Provider, error) {
+	m.record("ProviderDelete", id)
+	if m.ProviderDeleteFunc == nil {
+		return makeplans.Provider{}, ErrNotMocked
+	}
+	return m.ProviderDeleteFunc(id)
+}
+
+// SyncProviders records the call and calls SyncProvidersFunc
+func (m *Client) SyncProviders(resourceID int, serviceIDs []int) (created []makeplans.Provider, deleted []makeplans.Provider, err error) {
+	m.record("SyncProviders", resourceID, serviceIDs)
+	if m.SyncProvidersFunc == nil {
+		return nil, nil, ErrNotMocked
+	}
+	return m.SyncProvidersFunc(resourceID, serviceIDs)
+}
+
+// Events records the call and calls EventsFunc
+func (m *Client) Events() ([]makeplans.Event, error) {
+	m.record("Events")
+	if m.EventsFunc == nil {
+		return nil, ErrNotMocked
+	}
+	return m.EventsFunc()
+}
@@ -0,0 +1,46 @@
+package makeplansmock
+
+import (
+	"testing"
+
+	"github.com/drewwells/makeplans"
+)
+
+// countPeople stands in for consumer code depending on the interface
+func countPeople(ps makeplans.PersonService) (int, error) {
+	ppl, err := ps.People()
+	return len(ppl), err
+}
+
+func TestClient(t *testing.T) {
+	m := &Client{
+		PeopleFunc: func() ([]makeplans.Person, error) {
+			return []makeplans.Person{{ID: 1}, {ID: 2}}, nil
+		},
+	}
+
+	n, err := countPeople(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 2; n != e {
+		t.Errorf("got: %d wanted: %d", n, e)
+	}
+
+	_, err = m.BookingCancel(410369)
+	if err != ErrNotMocked {
+		t.Errorf("got: %v wanted: %s", err, ErrNotMocked)
+	}
+
+	calls := m.CallsTo("BookingCancel")
+	if e := 1; len(calls) != e {
+		t.Fatalf("got: %d wanted: %d", len(calls), e)
+	}
+	if e := 410369; calls[0].Args[0] != e {
+		t.Errorf("got: %v wanted: %d", calls[0].Args[0], e)
+	}
+
+	if e := 2; len(m.Calls()) != e {
+		t.Errorf("got: %d wanted: %d", len(m.Calls()), e)
+	}
+}
@@ -0,0 +1,201 @@
+package makeplanstest
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drewwells/makeplans"
+)
+
+func (s *Server) serveBookings(w http.ResponseWriter, r *http.Request, parts []string, id int) {
+	switch {
+	case len(parts) == 2 && parts[1] == "all":
+		s.listBookings(w, url.Values{}, true)
+	case id == 0 && r.Method == "GET":
+		s.listBookings(w, r.URL.Query(), false)
+	case id == 0 && r.Method == "POST":
+		s.createBooking(w, r)
+	default:
+		b, ok := s.bookings[id]
+		if !ok {
+			write(w, http.StatusNotFound, errNotFound)
+			return
+		}
+		switch {
+		case len(parts) == 3 && r.Method == "PUT":
+			s.transition(w, b, parts[2])
+		case r.Method == "DELETE":
+			s.transition(w, b, "delete")
+		case r.Method == "PUT":
+			if err := decode(r, "booking", &b); err != nil {
+				badRequest(w, err)
+				return
+			}
+			b.ID = id
+			b.UpdatedAt = s.now()
+			s.bookings[id] = b
+			write(w, http.StatusOK, wrapped("booking", b))
+		default:
+			write(w, http.StatusOK, wrapped("booking", b))
+		}
+	}
+}
+
+func queryInt(v url.Values, key string) int {
+	i, _ := strconv.Atoi(v.Get(key))
+	return i
+}
+
+func (s *Server) queryDate(v url.Values, key string) (time.Time, bool) {
+	t, err := time.ParseInLocation("2006-01-02", v.Get(key), s.location())
+	return t, err == nil
+}
+
+func (s *Server) listBookings(w http.ResponseWriter, v url.Values, all bool) {
+	serviceID := queryInt(v, "service_id")
+	resourceID := queryInt(v, "resource_id")
+	personID := queryInt(v, "person_id")
+	eventID := queryInt(v, "event_id")
+	externalID := v.Get("external_id")
+	collectionID := v.Get("collection_id")
+	include := map[string]bool{}
+	for _, name := range strings.Split(v.Get("include"), ",") {
+		include[name] = true
+	}
+	start, hasStart := s.queryDate(v, "start")
+	end, hasEnd := s.queryDate(v, "end")
+	end = end.AddDate(0, 0, 1)
+
+	ids := sortedIDs(len(s.bookings), func(f func(int)) {
+		for id, b := range s.bookings {
+			switch {
+			case !all && !b.State.Active():
+			case serviceID > 0 && b.ServiceID != serviceID:
+			case resourceID > 0 && b.ResourceID != resourceID:
+			case personID > 0 && b.PersonID != personID:
+			case eventID > 0 && b.EventID != eventID:
+			case len(externalID) > 0 && b.ExternalID != externalID:
+			case len(collectionID) > 0 && b.CollectionID != collectionID:
+			case hasStart && (b.BookedFrom == nil || b.BookedFrom.Before(start)):
+			case hasEnd && (b.BookedFrom == nil || !b.BookedFrom.Before(end)):
+			default:
+				f(id)
+			}
+		}
+	})
+	out := make([]interface{}, len(ids))
+	for i, id := range ids {
+		out[i] = wrapped("booking", s.embed(s.bookings[id], include))
+	}
+	write(w, http.StatusOK, out)
+}
+
+// embed attaches the included objects the way Makeplans does, service and
+// resource are abbreviated to id and title
+func (s *Server) embed(b makeplans.Booking, include map[string]bool) makeplans.Booking {
+	if p, ok := s.people[b.PersonID]; ok && include[makeplans.IncludePerson] {
+		b.Person = &p
+	}
+	if svc, ok := s.services[b.ServiceID]; ok && include[makeplans.IncludeService] {
+		b.Service = &makeplans.Service{ID: svc.ID, Title: svc.Title}
+	}
+	if res, ok := s.resources[b.ResourceID]; ok && include[makeplans.IncludeResource] {
+		b.Resource = &makeplans.Resource{ID: res.ID, Title: res.Title}
+	}
+	return b
+}
+
+// capacityError is the error Makeplans produces for unavailable times
+var capacityError = map[string]interface{}{
+	"error": map[string]string{
+		"description": makeplans.ErrBookingCapacityLimit.Error(),
+	},
+}
+
+func (s *Server) createBooking(w http.ResponseWriter, r *http.Request) {
+	var b makeplans.Booking
+	if err := decode(r, "booking", &b); err != nil {
+		badRequest(w, err)
+		return
+	}
+	svc, ok := s.services[b.ServiceID]
+	fe := makeplans.FieldError{}
+	if !ok {
+		fe["service_id"] = []string{"can't be blank"}
+	}
+	if b.BookedFrom == nil {
+		fe["booked_from"] = []string{"can't be blank"}
+	}
+	if b.PersonID > 0 {
+		if _, ok := s.people[b.PersonID]; !ok {
+			fe["person_id"] = []string{"is invalid"}
+		}
+	}
+	if len(fe) > 0 {
+		writeFieldError(w, fe)
+		return
+	}
+	if b.BookedTo == nil {
+		to := b.BookedFrom.Add(time.Duration(svc.Interval) * time.Minute)
+		b.BookedTo = &to
+	}
+	if b.Count == 0 {
+		b.Count = 1
+	}
+
+	// The booking must match a slot of a resource with room for the count
+	candidates := []int{b.ResourceID}
+	if b.ResourceID == 0 {
+		candidates = nil
+		for _, res := range s.serviceResources(svc.ID) {
+			candidates = append(candidates, res.ID)
+		}
+	}
+	b.ResourceID = 0
+	for _, id := range candidates {
+		if slot, ok := s.slotAt(svc, id, *b.BookedFrom); ok && slot.Free >= b.Count {
+			b.ResourceID = id
+			break
+		}
+	}
+	if b.ResourceID == 0 {
+		write(w, 422, capacityError)
+		return
+	}
+
+	b.ID = s.id()
+	if len(b.State) == 0 {
+		b.State = makeplans.BookingConfirmed
+	}
+	b.CreatedAt, b.UpdatedAt = s.now(), s.now()
+	s.bookings[b.ID] = b
+	write(w, http.StatusCreated, wrapped("booking", b))
+}
+
+// transition applies a Makeplans booking action. The fake verifies
+// bookings straight to confirmed.
+func (s *Server) transition(w http.ResponseWriter, b makeplans.Booking, action string) {
+	var to makeplans.BookingState
+	for _, t := range makeplans.BookingTransitions {
+		if t.From == b.State && t.Action == action &&
+			(len(to) == 0 || t.To == makeplans.BookingConfirmed) {
+			to = t.To
+		}
+	}
+	if len(to) == 0 {
+		if action == "delete" {
+			action = "remove"
+		}
+		writeFieldError(w, makeplans.FieldError{
+			"state": {`cannot transition via "` + action + `"`},
+		})
+		return
+	}
+	b.State = to
+	b.UpdatedAt = s.now()
+	s.bookings[b.ID] = b
+	write(w, http.StatusOK, wrapped("booking", b))
+}
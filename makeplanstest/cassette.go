@@ -0,0 +1,209 @@
+package makeplanstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Mode decides whether a Recorder talks to Makeplans or replays a cassette
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette and never touches the
+	// network
+	ModeReplay Mode = iota
+	// ModeRecord forwards requests and records them to the cassette
+	ModeRecord
+)
+
+// RecordEnv is the environment variable ModeFromEnv reads. Set it to
+// refresh cassettes, ie. MAKEPLANS_RECORD=1 go test ./...
+var RecordEnv = "MAKEPLANS_RECORD"
+
+// ModeFromEnv returns ModeRecord when RecordEnv is set, otherwise
+// ModeReplay
+func ModeFromEnv() Mode {
+	if len(os.Getenv(RecordEnv)) > 0 {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// ScrubbedToken replaces the account token anywhere it appears in a
+// recording
+var ScrubbedToken = "[token]"
+
+// Interaction is a single recorded request and response
+type Interaction struct {
+	Request struct {
+		Method string `json:"method"`
+		// URL is the path and query, the host depends on the account
+		URL  string `json:"url"`
+		Body string `json:"body,omitempty"`
+	} `json:"request"`
+	Response struct {
+		Status int         `json:"status"`
+		Header http.Header `json:"header,omitempty"`
+		Body   string      `json:"body"`
+	} `json:"response"`
+}
+
+// Cassette is the golden file format written by Recorder
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper recording Makeplans traffic to a
+// cassette or replaying it. Credentials are never written, the basic
+// auth header is dropped from recordings and the token is scrubbed from
+// bodies and urls.
+//
+//	rec, err := makeplanstest.NewRecorder("testdata/bookings.json",
+//		makeplanstest.ModeFromEnv())
+//	defer rec.Stop()
+//	client.Client = &http.Client{Transport: rec}
+type Recorder struct {
+	Path string
+	Mode Mode
+	// Transport performs requests while recording. Defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	played   []bool
+}
+
+// NewRecorder loads the cassette at path for replay, or starts an empty
+// cassette when recording
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{Path: path, Mode: mode}
+	if mode == ModeRecord {
+		return r, nil
+	}
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bs, &r.cassette)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	r.played = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.Mode == ModeRecord {
+		return r.record(req, body)
+	}
+	return r.replay(req, body)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	tr := r.Transport
+	if tr == nil {
+		tr = http.DefaultTransport
+	}
+	out := req.Clone(req.Context())
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp, err := tr.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	scrub := scrubber(req)
+	var in Interaction
+	in.Request.Method = req.Method
+	in.Request.URL = scrub(req.URL.RequestURI())
+	in.Request.Body = scrub(string(body))
+	in.Response.Status = resp.StatusCode
+	in.Response.Header = http.Header{}
+	if ct := resp.Header.Get("Content-Type"); len(ct) > 0 {
+		in.Response.Header.Set("Content-Type", ct)
+	}
+	in.Response.Body = scrub(string(respBody))
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// scrubber replaces the token of req. Makeplans passes the token as the
+// basic auth user.
+func scrubber(req *http.Request) func(string) string {
+	token, _, ok := req.BasicAuth()
+	if !ok || len(token) == 0 {
+		return func(s string) string { return s }
+	}
+	return func(s string) string {
+		return strings.Replace(s, token, ScrubbedToken, -1)
+	}
+}
+
+// replay serves the first unplayed interaction matching the request so
+// repeated requests can observe changing state
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Requests are compared the way they were recorded
+	scrub := scrubber(req)
+	uri := scrub(req.URL.RequestURI())
+	for i, in := range r.cassette.Interactions {
+		if r.played[i] || in.Request.Method != req.Method ||
+			in.Request.URL != uri || in.Request.Body != scrub(string(body)) {
+			continue
+		}
+		r.played[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header,
+			Body:          ioutil.NopCloser(bytes.NewBufferString(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%s: no recorded interaction for %s %s",
+		r.Path, req.Method, uri)
+}
+
+// Stop writes the cassette when recording. It is a no-op on replay.
+func (r *Recorder) Stop() error {
+	if r.Mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	bs, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.Path, append(bs, '\n'), 0644)
+}
@@ -0,0 +1,94 @@
+package makeplanstest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drewwells/makeplans"
+)
+
+func TestRecorder(t *testing.T) {
+	srv, svc, _ := seed(t)
+	path := filepath.Join(t.TempDir(), "services.json")
+
+	rec, err := NewRecorder(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := srv.Client()
+	client.Token = "secret-token"
+	client.Client = &http.Client{Transport: rec}
+
+	recorded, err := client.Services()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.ServiceDelete(svc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Services()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The token in the URL is scrubbed too
+	tokenParams := makeplans.BookingParams{ExternalID: client.Token}
+	if _, err = client.Bookings(tokenParams); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(bs), "secret-token") {
+		t.Error("token was recorded")
+	}
+
+	rec, err = NewRecorder(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Client = &http.Client{Transport: rec}
+
+	svcs, err := client.Services()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(svcs) != len(recorded) || svcs[0].Title != recorded[0].Title {
+		t.Errorf("got: %v wanted: %v", svcs, recorded)
+	}
+
+	del, err := client.ServiceDelete(svc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if del.Active {
+		t.Error("expected inactive service")
+	}
+
+	// Repeated requests replay in order
+	svcs, err = client.Services()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 0; len(svcs) != e {
+		t.Errorf("got: %d wanted: %d", len(svcs), e)
+	}
+
+	if _, err = client.Bookings(tokenParams); err != nil {
+		t.Errorf("got: %v wanted the scrubbed request replayed", err)
+	}
+
+	_, err = client.Bookings(makeplans.BookingParams{})
+	if err == nil {
+		t.Error("expected error for unrecorded request")
+	}
+}
@@ -0,0 +1,577 @@
+// Package makeplanstest provides an in-memory Makeplans API for testing
+// code built on makeplans.Client without a real account.
+//
+//	srv := makeplanstest.NewServer()
+//	defer srv.Close()
+//	svc := srv.AddService(makeplans.Service{Title: "Massage", Interval: 60})
+//	client := srv.Client()
+//	svcs, err := client.Services()
+package makeplanstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drewwells/makeplans"
+)
+
+// Server is a fake Makeplans account served over HTTP. All state is kept
+// in memory and may be seeded with the Add methods.
+type Server struct {
+	*httptest.Server
+
+	// Location is the account time zone used for slots. Defaults to UTC.
+	Location *time.Location
+	// Now is used for timestamps and as the default start of next
+	// available date searches. Defaults to time.Now.
+	Now func() time.Time
+
+	mu         sync.Mutex
+	nextID     int
+	services   map[int]makeplans.Service
+	resources  map[int]makeplans.Resource
+	providers  map[int]makeplans.Provider
+	people     map[int]makeplans.Person
+	bookings   map[int]makeplans.Booking
+	events     map[int]makeplans.Event
+	exceptions []makeplans.Exception
+}
+
+// NewServer starts an empty fake account. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		Now:       time.Now,
+		nextID:    1,
+		services:  map[int]makeplans.Service{},
+		resources: map[int]makeplans.Resource{},
+		providers: map[int]makeplans.Provider{},
+		people:    map[int]makeplans.Person{},
+		bookings:  map[int]makeplans.Booking{},
+		events:    map[int]makeplans.Event{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a makeplans.Client talking to the server
+func (s *Server) Client() *makeplans.Client {
+	c := makeplans.New("test", "token")
+	c.URL = s.URL
+	c.Resolver = func(urlTmpl string, accountName string) string {
+		return urlTmpl
+	}
+	c.Location = s.location()
+	return c
+}
+
+func (s *Server) location() *time.Location {
+	if s.Location != nil {
+		return s.Location
+	}
+	return time.UTC
+}
+
+func (s *Server) now() *time.Time {
+	t := s.Now()
+	return &t
+}
+
+func (s *Server) id() int {
+	id := s.nextID
+	s.nextID++
+	return id
+}
+
+// AddService stores the service as is, assigning an ID if missing.
+// Services only appear in lists when Active.
+func (s *Server) AddService(svc makeplans.Service) makeplans.Service {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if svc.ID == 0 {
+		svc.ID = s.id()
+	}
+	s.services[svc.ID] = svc
+	return svc
+}
+
+// AddResource stores the resource as is, assigning an ID if missing
+func (s *Server) AddResource(r makeplans.Resource) makeplans.Resource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.ID == 0 {
+		r.ID = s.id()
+	}
+	s.resources[r.ID] = r
+	return r
+}
+
+// AddProvider stores the provider as is, assigning an ID if missing
+func (s *Server) AddProvider(p makeplans.Provider) makeplans.Provider {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.ID == 0 {
+		p.ID = s.id()
+	}
+	s.providers[p.ID] = p
+	return p
+}
+
+// AddPerson stores the person as is, assigning an ID if missing
+func (s *Server) AddPerson(p makeplans.Person) makeplans.Person {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.ID == 0 {
+		p.ID = s.id()
+	}
+	s.people[p.ID] = p
+	return p
+}
+
+// AddBooking stores the booking as is, assigning an ID if missing. No
+// availability checks are made.
+func (s *Server) AddBooking(b makeplans.Booking) makeplans.Booking {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b.ID == 0 {
+		b.ID = s.id()
+	}
+	s.bookings[b.ID] = b
+	return b
+}
+
+// AddEvent stores the event as is, assigning an ID if missing
+func (s *Server) AddEvent(e makeplans.Event) makeplans.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.ID == 0 {
+		e.ID = s.id()
+	}
+	s.events[e.ID] = e
+	return e
+}
+
+// AddException closes a resource for slot calculations
+func (s *Server) AddException(ex makeplans.Exception) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exceptions = append(s.exceptions, ex)
+}
+
+// errNotFound mirrors the error document Makeplans returns for unknown ids
+var errNotFound = map[string]interface{}{
+	"error": map[string]string{"description": makeplans.ErrNotFound.Error()},
+}
+
+func write(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeFieldError(w http.ResponseWriter, fe makeplans.FieldError) {
+	write(w, 422, fe)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// BookingUpdate produces a double slash, Makeplans tolerates it
+	parts := strings.Split(strings.Trim(path.Clean(r.URL.Path), "/"), "/")
+	var id int
+	if len(parts) > 1 {
+		var err error
+		id, err = strconv.Atoi(parts[1])
+		if err != nil && parts[1] != "all" {
+			write(w, http.StatusNotFound, errNotFound)
+			return
+		}
+	}
+
+	switch parts[0] {
+	case "services":
+		s.serveServices(w, r, parts, id)
+	case "resources":
+		s.serveResources(w, r, parts, id)
+	case "providers":
+		s.serveProviders(w, r, id)
+	case "people":
+		s.servePeople(w, r, id)
+	case "bookings":
+		s.serveBookings(w, r, parts, id)
+	case "events":
+		s.serveEvents(w, r, id)
+	default:
+		write(w, http.StatusNotFound, errNotFound)
+	}
+}
+
+// decode merges the wrapped request body, ie. {"service": {...}}, into v
+func decode(r *http.Request, key string, v interface{}) error {
+	bs, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	wrap := map[string]json.RawMessage{}
+	if err := json.Unmarshal(bs, &wrap); err != nil {
+		return err
+	}
+	raw, ok := wrap[key]
+	if !ok {
+		return fmt.Errorf("missing %s", key)
+	}
+	return json.Unmarshal(raw, v)
+}
+
+func badRequest(w http.ResponseWriter, err error) {
+	write(w, http.StatusBadRequest, map[string]interface{}{
+		"error": map[string]string{"description": err.Error()},
+	})
+}
+
+func wrapped(key string, v interface{}) map[string]interface{} {
+	return map[string]interface{}{key: v}
+}
+
+func sortedIDs(n int, each func(func(int))) []int {
+	ids := make([]int, 0, n)
+	each(func(id int) { ids = append(ids, id) })
+	sort.Ints(ids)
+	return ids
+}
+
+func (s *Server) serveServices(w http.ResponseWriter, r *http.Request, parts []string, id int) {
+	if len(parts) == 3 {
+		svc, ok := s.services[id]
+		if !ok {
+			write(w, http.StatusNotFound, errNotFound)
+			return
+		}
+		switch parts[2] {
+		case "slots":
+			s.serveSlots(w, r, svc)
+		case "next_available_date":
+			s.serveNextDate(w, r, svc)
+		default:
+			write(w, http.StatusNotFound, errNotFound)
+		}
+		return
+	}
+
+	switch {
+	case id == 0 && r.Method == "GET":
+		ids := sortedIDs(len(s.services), func(f func(int)) {
+			for id, svc := range s.services {
+				if svc.Active {
+					f(id)
+				}
+			}
+		})
+		out := make([]interface{}, len(ids))
+		for i, id := range ids {
+			out[i] = wrapped("service", s.services[id])
+		}
+		write(w, http.StatusOK, out)
+	case id == 0 && r.Method == "POST":
+		var svc makeplans.Service
+		if err := decode(r, "service", &svc); err != nil {
+			badRequest(w, err)
+			return
+		}
+		fe := makeplans.FieldError{}
+		if len(svc.Title) == 0 {
+			fe["title"] = []string{"can't be blank"}
+		}
+		if svc.Interval <= 0 {
+			fe["interval"] = []string{"can't be blank"}
+		}
+		if len(fe) > 0 {
+			writeFieldError(w, fe)
+			return
+		}
+		svc.ID = s.id()
+		svc.CreatedAt, svc.UpdatedAt = s.now(), s.now()
+		s.services[svc.ID] = svc
+		write(w, http.StatusCreated, wrapped("service", svc))
+	default:
+		svc, ok := s.services[id]
+		if !ok {
+			write(w, http.StatusNotFound, errNotFound)
+			return
+		}
+		switch r.Method {
+		case "PUT":
+			if err := decode(r, "service", &svc); err != nil {
+				badRequest(w, err)
+				return
+			}
+			svc.ID = id
+			svc.UpdatedAt = s.now()
+		case "DELETE":
+			svc.Active = false
+			svc.UpdatedAt = s.now()
+		}
+		s.services[id] = svc
+		write(w, http.StatusOK, wrapped("service", svc))
+	}
+}
+
+func (s *Server) serveResources(w http.ResponseWriter, r *http.Request, parts []string, id int) {
+	if len(parts) == 3 && parts[2] == "slots" {
+		if _, ok := s.resources[id]; !ok {
+			write(w, http.StatusNotFound, errNotFound)
+			return
+		}
+		s.serveResourceSlots(w, r, id)
+		return
+	}
+
+	switch {
+	case id == 0 && r.Method == "GET":
+		ids := sortedIDs(len(s.resources), func(f func(int)) {
+			for id := range s.resources {
+				f(id)
+			}
+		})
+		out := make([]interface{}, len(ids))
+		for i, id := range ids {
+			out[i] = wrapped("resource", s.resources[id])
+		}
+		write(w, http.StatusOK, out)
+	case id == 0 && r.Method == "POST":
+		var res makeplans.Resource
+		if err := decode(r, "resource", &res); err != nil {
+			badRequest(w, err)
+			return
+		}
+		if len(res.Title) == 0 {
+			writeFieldError(w, makeplans.FieldError{
+				"title": {"can't be blank"},
+			})
+			return
+		}
+		if _, err := res.OpeningHours(); err != nil {
+			writeFieldError(w, makeplans.FieldError{
+				"opening_hours": {err.Error()},
+			})
+			return
+		}
+		if res.Capacity == 0 {
+			res.Capacity = 1
+		}
+		res.ID = s.id()
+		res.CreatedAt, res.UpdatedAt = s.now(), s.now()
+		s.resources[res.ID] = res
+		write(w, http.StatusCreated, wrapped("resource", res))
+	default:
+		res, ok := s.resources[id]
+		if !ok {
+			write(w, http.StatusNotFound, errNotFound)
+			return
+		}
+		switch r.Method {
+		case "GET":
+			res.Services = s.providedServices(id)
+		case "PUT":
+			if err := decode(r, "resource", &res); err != nil {
+				badRequest(w, err)
+				return
+			}
+			if _, err := res.OpeningHours(); err != nil {
+				writeFieldError(w, makeplans.FieldError{
+					"opening_hours": {err.Error()},
+				})
+				return
+			}
+			res.ID = id
+			res.UpdatedAt = s.now()
+			s.resources[id] = res
+		case "DELETE":
+			delete(s.resources, id)
+		}
+		write(w, http.StatusOK, wrapped("resource", res))
+	}
+}
+
+func (s *Server) providedServices(resourceID int) []makeplans.Service {
+	var svcs []makeplans.Service
+	for _, p := range s.providers {
+		if p.ResourceID != resourceID {
+			continue
+		}
+		if svc, ok := s.services[p.ServiceID]; ok {
+			svcs = append(svcs, svc)
+		}
+	}
+	sort.Slice(svcs, func(i, j int) bool { return svcs[i].ID < svcs[j].ID })
+	return svcs
+}
+
+func (s *Server) serveProviders(w http.ResponseWriter, r *http.Request, id int) {
+	switch {
+	case id == 0 && r.Method == "GET":
+		ids := sortedIDs(len(s.providers), func(f func(int)) {
+			for id := range s.providers {
+				f(id)
+			}
+		})
+		out := make([]interface{}, len(ids))
+		for i, id := range ids {
+			out[i] = wrapped("provider", s.providers[id])
+		}
+		write(w, http.StatusOK, out)
+	case id == 0 && r.Method == "POST":
+		var p makeplans.Provider
+		if err := decode(r, "provider", &p); err != nil {
+			badRequest(w, err)
+			return
+		}
+		p.ID = 0
+		if fe := s.validateProvider(p); len(fe) > 0 {
+			writeFieldError(w, fe)
+			return
+		}
+		p.ID = s.id()
+		p.CreatedAt, p.UpdatedAt = s.now(), s.now()
+		s.providers[p.ID] = p
+		write(w, http.StatusCreated, wrapped("provider", p))
+	default:
+		p, ok := s.providers[id]
+		if !ok {
+			write(w, http.StatusNotFound, errNotFound)
+			return
+		}
+		switch r.Method {
+		case "PUT":
+			if err := decode(r, "provider", &p); err != nil {
+				badRequest(w, err)
+				return
+			}
+			p.ID = id
+			if fe := s.validateProvider(p); len(fe) > 0 {
+				writeFieldError(w, fe)
+				return
+			}
+			p.UpdatedAt = s.now()
+			s.providers[id] = p
+		case "DELETE":
+			delete(s.providers, id)
+		}
+		write(w, http.StatusOK, wrapped("provider", p))
+	}
+}
+
+func (s *Server) validateProvider(p makeplans.Provider) makeplans.FieldError {
+	fe := makeplans.FieldError{}
+	if _, ok := s.resources[p.ResourceID]; !ok {
+		fe["resource_id"] = []string{"can't be blank"}
+	}
+	if _, ok := s.services[p.ServiceID]; !ok {
+		fe["service_id"] = []string{"can't be blank"}
+	}
+	for _, other := range s.providers {
+		if other.ID != p.ID && other.ResourceID == p.ResourceID &&
+			other.ServiceID == p.ServiceID {
+			fe["service_id"] = []string{"has already been taken"}
+		}
+	}
+	return fe
+}
+
+func (s *Server) servePeople(w http.ResponseWriter, r *http.Request, id int) {
+	switch {
+	case id == 0 && r.Method == "GET":
+		ids := sortedIDs(len(s.people), func(f func(int)) {
+			for id := range s.people {
+				f(id)
+			}
+		})
+		out := make([]interface{}, len(ids))
+		for i, id := range ids {
+			out[i] = wrapped("person", s.people[id])
+		}
+		write(w, http.StatusOK, out)
+	case id == 0 && r.Method == "POST":
+		var p makeplans.Person
+		if err := decode(r, "person", &p); err != nil {
+			badRequest(w, err)
+			return
+		}
+		p.ID = 0
+		if fe := s.validatePerson(p); len(fe) > 0 {
+			writeFieldError(w, fe)
+			return
+		}
+		p.ID = s.id()
+		p.CreatedAt, p.UpdatedAt = s.now(), s.now()
+		s.people[p.ID] = p
+		write(w, http.StatusCreated, wrapped("person", p))
+	default:
+		p, ok := s.people[id]
+		if !ok {
+			write(w, http.StatusNotFound, errNotFound)
+			return
+		}
+		if r.Method == "PUT" {
+			if err := decode(r, "person", &p); err != nil {
+				badRequest(w, err)
+				return
+			}
+			p.ID = id
+			if fe := s.validatePerson(p); len(fe) > 0 {
+				writeFieldError(w, fe)
+				return
+			}
+			p.UpdatedAt = s.now()
+			s.people[id] = p
+		}
+		write(w, http.StatusOK, wrapped("person", p))
+	}
+}
+
+func (s *Server) validatePerson(p makeplans.Person) makeplans.FieldError {
+	fe := makeplans.FieldError{}
+	if len(p.Name) == 0 && len(p.Email) == 0 && len(p.PhoneNumber) == 0 {
+		fe["name"] = []string{"can't be blank"}
+	}
+	if len(p.Email) == 0 {
+		return fe
+	}
+	for _, other := range s.people {
+		if other.ID != p.ID && strings.EqualFold(other.Email, p.Email) {
+			fe["email"] = []string{"has already been taken"}
+		}
+	}
+	return fe
+}
+
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request, id int) {
+	if id != 0 {
+		e, ok := s.events[id]
+		if !ok {
+			write(w, http.StatusNotFound, errNotFound)
+			return
+		}
+		write(w, http.StatusOK, wrapped("event", e))
+		return
+	}
+	ids := sortedIDs(len(s.events), func(f func(int)) {
+		for id := range s.events {
+			f(id)
+		}
+	})
+	out := make([]interface{}, len(ids))
+	for i, id := range ids {
+		out[i] = wrapped("event", s.events[id])
+	}
+	write(w, http.StatusOK, out)
+}
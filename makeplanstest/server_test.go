@@ -0,0 +1,240 @@
+package makeplanstest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drewwells/makeplans"
+)
+
+func seed(t *testing.T) (*Server, makeplans.Service, makeplans.Resource) {
+	srv := NewServer()
+	srv.Location = time.FixedZone("CST", -6*60*60)
+	srv.Now = func() time.Time {
+		return time.Date(2015, 12, 10, 9, 0, 0, 0, srv.Location)
+	}
+	client := srv.Client()
+	svc, err := client.ServiceCreate(makeplans.Service{
+		Active:          true,
+		Title:           "Kickboxing",
+		Interval:        60,
+		BookingCapacity: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := client.MakeResource(makeplans.Resource{
+		Title:           "jill bob",
+		OpeningHoursMon: []string{"12:00", "14:00"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.MakeProvider(makeplans.Provider{
+		ResourceID: res.ID,
+		ServiceID:  svc.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, svc, res
+}
+
+func TestServer_services(t *testing.T) {
+	srv, svc, _ := seed(t)
+	defer srv.Close()
+	client := srv.Client()
+
+	_, err := client.ServiceCreate(makeplans.Service{Interval: 30})
+	fe, ok := err.(makeplans.FieldError)
+	if !ok {
+		t.Fatalf("got: %v wanted FieldError", err)
+	}
+	if _, ok := fe["title"]; !ok {
+		t.Errorf("expected title error got: %s", fe)
+	}
+
+	client.Currency = "NOK"
+	svc.Price = &makeplans.Money{Amount: 2000}
+	saved, err := client.ServiceSave(svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "20.00 NOK"; saved.Price.String() != e {
+		t.Errorf("got: %s wanted: %s", saved.Price, e)
+	}
+
+	_, err = client.ServiceDelete(svc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svcs, err := client.Services()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 0; len(svcs) != e {
+		t.Errorf("got: %d wanted: %d", len(svcs), e)
+	}
+}
+
+func TestServer_bookings(t *testing.T) {
+	srv, svc, res := seed(t)
+	defer srv.Close()
+	client := srv.Client()
+
+	day, err := client.NextAvailableDate(svc.ID, makeplans.SlotParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := time.Date(2015, 12, 14, 0, 0, 0, 0, srv.Location); !day.Equal(e) {
+		t.Fatalf("got: %s wanted: %s", day, e)
+	}
+
+	slots, err := client.ServiceSlot(svc.ID, makeplans.SlotParams{
+		From: day,
+		To:   day,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 2; len(slots) != e {
+		t.Fatalf("got: %d wanted: %d", len(slots), e)
+	}
+
+	person, err := client.MakePerson(makeplans.Person{
+		Name:  "Test User",
+		Email: "test@example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.MakePerson(makeplans.Person{Email: "test@example.com"})
+	if err != makeplans.ErrEmailTaken {
+		t.Errorf("got: %v wanted: %s", err, makeplans.ErrEmailTaken)
+	}
+
+	book, err := client.MakeBooking(makeplans.Booking{
+		ServiceID:  svc.ID,
+		PersonID:   person.ID,
+		BookedFrom: slots[0].Timestamp,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.ResourceID != res.ID {
+		t.Errorf("got: %d wanted: %d", book.ResourceID, res.ID)
+	}
+
+	_, err = client.MakeBooking(makeplans.Booking{
+		ServiceID:  svc.ID,
+		BookedFrom: slots[0].Timestamp,
+	})
+	if err != makeplans.ErrBookingCapacityLimit {
+		t.Errorf("got: %v wanted: %s", err, makeplans.ErrBookingCapacityLimit)
+	}
+
+	slots, err = client.ServiceSlot(svc.ID, makeplans.SlotParams{
+		From:     day,
+		To:       day,
+		OnlyFree: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 1; len(slots) != e {
+		t.Fatalf("got: %d wanted: %d", len(slots), e)
+	}
+
+	books, err := client.Bookings(makeplans.BookingParams{
+		PersonID: person.ID,
+		Include:  []string{makeplans.IncludePerson},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 1; len(books) != e {
+		t.Fatalf("got: %d wanted: %d", len(books), e)
+	}
+	if books[0].Person == nil || books[0].Person.Name != person.Name {
+		t.Errorf("expected embedded person got: %v", books[0].Person)
+	}
+
+	book, err = client.TransitionBooking(book.ID, makeplans.BookingCancelled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := makeplans.BookingCancelled; book.State != e {
+		t.Errorf("got: %s wanted: %s", book.State, e)
+	}
+
+	_, err = client.BookingDelete(book.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.BookingDelete(book.ID)
+	if fe, ok := err.(makeplans.FieldError); !ok || len(fe["state"]) == 0 {
+		t.Errorf("got: %v wanted state error", err)
+	}
+
+	books, err = client.Bookings(makeplans.BookingParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 0; len(books) != e {
+		t.Errorf("got: %d wanted: %d", len(books), e)
+	}
+
+	books, err = client.BookingAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 1; len(books) != e {
+		t.Errorf("got: %d wanted: %d", len(books), e)
+	}
+}
+
+func TestServer_resources(t *testing.T) {
+	srv, svc, res := seed(t)
+	defer srv.Close()
+	client := srv.Client()
+
+	_, err := client.Resource(res.ID + 100)
+	if err != makeplans.ErrNotFound {
+		t.Errorf("got: %v wanted: %s", err, makeplans.ErrNotFound)
+	}
+
+	got, err := client.Resource(res.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Services) != 1 || got.Services[0].ID != svc.ID {
+		t.Errorf("expected service %d got: %v", svc.ID, got.Services)
+	}
+
+	got.OpeningHoursMon = []string{"14:00", "12:00"}
+	_, err = client.ResourceUpdate(got)
+	if _, ok := err.(makeplans.FieldError); !ok {
+		t.Errorf("got: %v wanted FieldError", err)
+	}
+
+	ps, err := client.Providers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 1; len(ps) != e {
+		t.Fatalf("got: %d wanted: %d", len(ps), e)
+	}
+	_, err = client.MakeProvider(ps[0])
+	if _, ok := err.(makeplans.FieldError); !ok {
+		t.Errorf("got: %v wanted FieldError", err)
+	}
+
+	srv.AddEvent(makeplans.Event{Title: "Open day", ServiceID: svc.ID})
+	evts, err := client.Events()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 1; len(evts) != e {
+		t.Errorf("got: %d wanted: %d", len(evts), e)
+	}
+}
@@ -0,0 +1,136 @@
+package makeplanstest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drewwells/makeplans"
+)
+
+// serviceResources are the resources providing the service
+func (s *Server) serviceResources(serviceID int) []makeplans.Resource {
+	var ress []makeplans.Resource
+	for _, p := range s.providers {
+		if res, ok := s.resources[p.ResourceID]; ok && p.ServiceID == serviceID {
+			ress = append(ress, res)
+		}
+	}
+	sort.Slice(ress, func(i, j int) bool { return ress[i].ID < ress[j].ID })
+	return ress
+}
+
+func (s *Server) availability(svc makeplans.Service) makeplans.Availability {
+	bookings := make([]makeplans.Booking, 0, len(s.bookings))
+	for _, b := range s.bookings {
+		bookings = append(bookings, b)
+	}
+	return makeplans.Availability{
+		Service:    svc,
+		Resources:  s.serviceResources(svc.ID),
+		Exceptions: s.exceptions,
+		Bookings:   bookings,
+		Location:   s.location(),
+	}
+}
+
+// slotAt finds the slot of a single resource starting at t
+func (s *Server) slotAt(svc makeplans.Service, resourceID int, t time.Time) (makeplans.Slot, bool) {
+	slots, err := s.availability(svc).Slots(makeplans.SlotParams{
+		From:              t.In(s.location()),
+		To:                t.In(s.location()),
+		SelectedResources: []int{resourceID},
+	})
+	if err != nil {
+		return makeplans.Slot{}, false
+	}
+	for _, slot := range slots {
+		if slot.Timestamp.Equal(t) {
+			return slot, true
+		}
+	}
+	return makeplans.Slot{}, false
+}
+
+func (s *Server) slotParams(r *http.Request) makeplans.SlotParams {
+	v := r.URL.Query()
+	today := s.Now().In(s.location())
+	params := makeplans.SlotParams{
+		From:             today,
+		To:               today,
+		OnlyFree:         v.Get("only_free") == "true",
+		MinimumFreeCount: queryInt(v, "minimum_free_count"),
+		DayBooking:       v.Get("day_booking") == "true",
+	}
+	if from, ok := s.queryDate(v, "from"); ok {
+		params.From = from
+	}
+	if to, ok := s.queryDate(v, "to"); ok {
+		params.To = to
+	}
+	if sel := v.Get("selected_resources"); len(sel) > 0 {
+		for _, id := range strings.Split(sel, ",") {
+			i, _ := strconv.Atoi(id)
+			params.SelectedResources = append(params.SelectedResources, i)
+		}
+	}
+	return params
+}
+
+func (s *Server) writeSlots(w http.ResponseWriter, svc makeplans.Service, params makeplans.SlotParams) {
+	slots, err := s.availability(svc).Slots(params)
+	if err != nil {
+		badRequest(w, err)
+		return
+	}
+	out := make([]interface{}, len(slots))
+	for i, slot := range slots {
+		out[i] = wrapped("slot", slot)
+	}
+	write(w, http.StatusOK, out)
+}
+
+func (s *Server) serveSlots(w http.ResponseWriter, r *http.Request, svc makeplans.Service) {
+	s.writeSlots(w, svc, s.slotParams(r))
+}
+
+// serveResourceSlots uses the lowest id service provided by the resource
+// to decide the slot interval
+func (s *Server) serveResourceSlots(w http.ResponseWriter, r *http.Request, resourceID int) {
+	svcs := s.providedServices(resourceID)
+	if len(svcs) == 0 {
+		write(w, http.StatusOK, []interface{}{})
+		return
+	}
+	params := s.slotParams(r)
+	params.SelectedResources = []int{resourceID}
+	s.writeSlots(w, svcs[0], params)
+}
+
+// searchDays limits how far ahead next_available_date looks
+const searchDays = 366
+
+func (s *Server) serveNextDate(w http.ResponseWriter, r *http.Request, svc makeplans.Service) {
+	params := s.slotParams(r)
+	params.OnlyFree = true
+	a := s.availability(svc)
+	day := params.From
+	for i := 0; i < searchDays; i++ {
+		params.From, params.To = day, day
+		slots, err := a.Slots(params)
+		if err != nil {
+			badRequest(w, err)
+			return
+		}
+		if len(slots) > 0 {
+			write(w, http.StatusOK, []interface{}{
+				map[string]string{"available_date": day.Format("2006-01-02")},
+			})
+			return
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	write(w, http.StatusOK, []interface{}{})
+}
@@ -0,0 +1,175 @@
+package makeplans
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Money is a decimal amount with two digits of precision. Makeplans sends
+// prices as strings, "20.0", or null. A nil *Money represents null.
+type Money struct {
+	// Amount is in hundredths of the currency unit, ie. cents
+	Amount int64
+	// Currency is the ISO 4217 code from the account settings. Makeplans
+	// does not send it with prices, Client fills it from Client.Currency.
+	Currency string
+}
+
+// ErrCurrencyMismatch is returned by arithmetic on different currencies
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// ParseMoney reads a decimal string like "20.0" or "19.99"
+func ParseMoney(s string, currency string) (Money, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(s, "-")
+	// ParseInt accepts another sign, "--5" would come out positive
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	whole, frac := digits, ""
+	if i := strings.Index(digits, "."); i >= 0 {
+		whole, frac = digits[:i], digits[i+1:]
+	}
+	if len(whole) == 0 && len(frac) == 0 {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	if len(frac) > 2 {
+		if strings.Trim(frac[2:], "0") != "" {
+			return Money{}, fmt.Errorf("invalid amount %q: more than 2 decimals", s)
+		}
+		frac = frac[:2]
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	if len(whole) == 0 {
+		whole = "0"
+	}
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	cents, err := strconv.ParseInt(frac, 10, 64)
+	if err != nil || strings.ContainsAny(frac, "+-") {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	amount := units*100 + cents
+	if neg {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Decimal formats the amount without currency, ie. "20.00"
+func (m Money) Decimal() string {
+	a := m.Amount
+	sign := ""
+	if a < 0 {
+		sign = "-"
+		a = -a
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, a/100, a%100)
+}
+
+// String formats the amount followed by currency, ie. "20.00 NOK"
+func (m Money) String() string {
+	if len(m.Currency) == 0 {
+		return m.Decimal()
+	}
+	return m.Decimal() + " " + m.Currency
+}
+
+// IsZero reports if the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) currency(o Money) (string, error) {
+	switch {
+	case m.Currency == o.Currency || len(o.Currency) == 0:
+		return m.Currency, nil
+	case len(m.Currency) == 0:
+		return o.Currency, nil
+	}
+	return "", ErrCurrencyMismatch
+}
+
+// Add returns m + o. An empty currency adopts the other currency.
+func (m Money) Add(o Money) (Money, error) {
+	cur, err := m.currency(o)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: cur}, nil
+}
+
+// Sub returns m - o. An empty currency adopts the other currency.
+func (m Money) Sub(o Money) (Money, error) {
+	cur, err := m.currency(o)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount - o.Amount, Currency: cur}, nil
+}
+
+// Mul returns m times n, ie. a price times a booking count
+func (m Money) Mul(n int) Money {
+	return Money{Amount: m.Amount * int64(n), Currency: m.Currency}
+}
+
+// MarshalJSON writes the decimal string Makeplans expects
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Decimal())
+}
+
+// UnmarshalJSON reads decimal strings and numbers. Currency is untouched.
+func (m *Money) UnmarshalJSON(bs []byte) error {
+	var s string
+	if err := json.Unmarshal(bs, &s); err != nil {
+		var n json.Number
+		if err := json.Unmarshal(bs, &n); err != nil {
+			return fmt.Errorf("invalid price %s", bs)
+		}
+		s = n.String()
+	}
+	parsed, err := ParseMoney(s, m.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// BookingsTotal sums the price of every active booking times its count.
+// Services without a price are free. Bookings for services missing from
+// services are an error.
+func BookingsTotal(bookings []Booking, services []Service) (Money, error) {
+	byID := map[int]Service{}
+	for _, svc := range services {
+		byID[svc.ID] = svc
+	}
+	var total Money
+	for _, b := range bookings {
+		if !b.State.Active() {
+			continue
+		}
+		svc, ok := byID[b.ServiceID]
+		if !ok {
+			return Money{}, fmt.Errorf("booking %d: unknown service %d",
+				b.ID, b.ServiceID)
+		}
+		if svc.Price == nil {
+			continue
+		}
+		var err error
+		total, err = total.Add(svc.Price.Mul(bookingCount(b)))
+		if err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
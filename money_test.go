@@ -0,0 +1,101 @@
+package makeplans
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMoney_parse(t *testing.T) {
+	cases := map[string]int64{
+		"20.0":   2000,
+		"19.99":  1999,
+		"115":    11500,
+		"0.5":    50,
+		"-3.25":  -325,
+		"12.500": 1250,
+	}
+	for in, e := range cases {
+		m, err := ParseMoney(in, "NOK")
+		if err != nil {
+			t.Errorf("%s: %s", in, err)
+			continue
+		}
+		if m.Amount != e {
+			t.Errorf("%s got: %d wanted: %d", in, m.Amount, e)
+		}
+	}
+
+	for _, in := range []string{"abc", "1.234", "1.-5", "--5", "-+5", "+-5", "1.+5", "", "-", "."} {
+		if _, err := ParseMoney(in, ""); err == nil {
+			t.Errorf("expected error for %q", in)
+		}
+	}
+
+	if e := "-3.05 NOK"; (Money{Amount: -305, Currency: "NOK"}).String() != e {
+		t.Errorf("got: %s wanted: %s", Money{Amount: -305, Currency: "NOK"}, e)
+	}
+}
+
+func TestMoney_json(t *testing.T) {
+	var svc Service
+	err := json.Unmarshal([]byte(`{"price":"20.0"}`), &svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.Price == nil || svc.Price.Amount != 2000 {
+		t.Fatalf("got: %v wanted: 20.00", svc.Price)
+	}
+
+	svc = Service{}
+	err = json.Unmarshal([]byte(`{"price":null}`), &svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc.Price != nil {
+		t.Errorf("got: %s wanted nil", svc.Price)
+	}
+
+	bs, err := json.Marshal(Service{Price: &Money{Amount: 11500}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := `{"price":"115.00"}`; string(bs) != e {
+		t.Errorf("got: %s wanted: %s", bs, e)
+	}
+}
+
+func TestMoney_total(t *testing.T) {
+	price := Money{Amount: 2000, Currency: "NOK"}
+	svcs := []Service{
+		{ID: 1, Price: &price},
+		{ID: 2},
+	}
+	now := time.Now()
+	bookings := []Booking{
+		{ID: 1, ServiceID: 1, Count: 2, BookedFrom: &now},
+		{ID: 2, ServiceID: 1, Count: 1},
+		{ID: 3, ServiceID: 1, Count: 1, State: BookingCancelled},
+		{ID: 4, ServiceID: 2, Count: 1},
+	}
+	total, err := BookingsTotal(bookings, svcs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "60.00 NOK"; total.String() != e {
+		t.Errorf("got: %s wanted: %s", total, e)
+	}
+
+	_, err = price.Add(Money{Amount: 100, Currency: "SEK"})
+	if err != ErrCurrencyMismatch {
+		t.Errorf("got: %v wanted: %s", err, ErrCurrencyMismatch)
+	}
+
+	diff, err := price.Sub(Money{Amount: 2500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := "-5.00 NOK"; diff.String() != e {
+		t.Errorf("got: %s wanted: %s", diff, e)
+	}
+}
@@ -0,0 +1,241 @@
+package makeplans
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// jsonKind remembers the shape a setting arrived in so it is sent back
+// the same way
+type jsonKind int
+
+const (
+	kindUnset jsonKind = iota
+	kindBool
+	kindString
+	kindObject
+)
+
+// Notification is a mail or sms setting of a service. A nil *Notification
+// means Makeplans uses the account default. Makeplans may send the setting
+// as a flag, as custom message text or as an object, each is read into the
+// same fields and written back in the shape it arrived in. Objects are
+// written back with the keys they arrived with, changes are laid over them.
+type Notification struct {
+	Enabled bool
+	// Subject is only used by mail
+	Subject string
+	Text    string
+
+	kind jsonKind
+	// extra is the object as received
+	extra map[string]json.RawMessage
+}
+
+// NewNotification returns an enabled notification with custom text
+func NewNotification(text string) *Notification {
+	return &Notification{Enabled: true, Text: text}
+}
+
+// UnmarshalJSON accepts true/false, "text" or an object
+func (n *Notification) UnmarshalJSON(bs []byte) error {
+	*n = Notification{}
+	switch b := bytes.TrimSpace(bs); {
+	case len(b) == 0:
+		return nil
+	case b[0] == 't' || b[0] == 'f':
+		n.kind = kindBool
+		return json.Unmarshal(b, &n.Enabled)
+	case b[0] == '"':
+		n.kind = kindString
+		n.Enabled = true
+		return json.Unmarshal(b, &n.Text)
+	case b[0] == '{':
+		n.kind = kindObject
+		n.extra = map[string]json.RawMessage{}
+		if err := json.Unmarshal(b, &n.extra); err != nil {
+			return err
+		}
+		n.Enabled = true
+		if err := readKey(n.extra, "enabled", &n.Enabled); err != nil {
+			return err
+		}
+		if err := readKey(n.extra, "subject", &n.Subject); err != nil {
+			return err
+		}
+		return readKey(n.extra, "text", &n.Text)
+	}
+	return fmt.Errorf("invalid notification setting %s", bs)
+}
+
+// MarshalJSON writes the setting back in its original shape. New settings
+// are written as text when set, otherwise as a flag.
+func (n Notification) MarshalJSON() ([]byte, error) {
+	kind := n.kind
+	if kind == kindUnset {
+		kind = kindBool
+		if len(n.Text) > 0 || len(n.Subject) > 0 {
+			kind = kindString
+		}
+	}
+	// A flag or plain text can't hold everything set, upgrade to an object
+	if kind == kindBool && (len(n.Text) > 0 || len(n.Subject) > 0) ||
+		kind == kindString && (!n.Enabled || len(n.Subject) > 0) {
+		kind = kindObject
+	}
+	switch kind {
+	case kindBool:
+		return json.Marshal(n.Enabled)
+	case kindString:
+		return json.Marshal(n.Text)
+	}
+	obj, err := n.object()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+// object lays the fields over the object received. Enabled is implied
+// when an object leaves it out, new objects always state it.
+func (n Notification) object() (map[string]json.RawMessage, error) {
+	obj := copyKeys(n.extra)
+	if err := putKey(obj, "enabled", n.Enabled, !n.Enabled || n.kind != kindObject); err != nil {
+		return nil, err
+	}
+	if err := putKey(obj, "subject", n.Subject, len(n.Subject) > 0); err != nil {
+		return nil, err
+	}
+	if err := putKey(obj, "text", n.Text, len(n.Text) > 0); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Reminder is the sms reminder setting of a service. Before is how long
+// ahead of the booking the reminder is sent, setting it forces the object
+// shape with "minutes_before".
+type Reminder struct {
+	Notification
+	Before time.Duration
+}
+
+// UnmarshalJSON accepts the Notification shapes plus "minutes_before"
+func (r *Reminder) UnmarshalJSON(bs []byte) error {
+	*r = Reminder{}
+	if err := r.Notification.UnmarshalJSON(bs); err != nil {
+		return err
+	}
+	var minutes int
+	if err := readKey(r.extra, "minutes_before", &minutes); err != nil {
+		return err
+	}
+	r.Before = time.Duration(minutes) * time.Minute
+	return nil
+}
+
+// MarshalJSON writes the reminder, as an object when Before is set or the
+// reminder arrived as one
+func (r Reminder) MarshalJSON() ([]byte, error) {
+	if r.Before == 0 && r.kind != kindObject {
+		return r.Notification.MarshalJSON()
+	}
+	obj, err := r.Notification.object()
+	if err != nil {
+		return nil, err
+	}
+	if err := putKey(obj, "minutes_before", int(r.Before/time.Minute), r.Before != 0); err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+// Template selects the booking page template of a service. Makeplans may
+// send the id, the name or an object with both.
+type Template struct {
+	ID   int
+	Name string
+
+	kind  jsonKind
+	extra map[string]json.RawMessage
+}
+
+// UnmarshalJSON accepts an id, a name or an object
+func (t *Template) UnmarshalJSON(bs []byte) error {
+	*t = Template{}
+	switch b := bytes.TrimSpace(bs); {
+	case len(b) == 0:
+		return nil
+	case b[0] == '"':
+		t.kind = kindString
+		return json.Unmarshal(b, &t.Name)
+	case b[0] == '{':
+		t.kind = kindObject
+		t.extra = map[string]json.RawMessage{}
+		if err := json.Unmarshal(b, &t.extra); err != nil {
+			return err
+		}
+		if err := readKey(t.extra, "id", &t.ID); err != nil {
+			return err
+		}
+		return readKey(t.extra, "name", &t.Name)
+	default:
+		return json.Unmarshal(b, &t.ID)
+	}
+}
+
+// MarshalJSON writes the template back in its original shape. New
+// templates are written as the id.
+func (t Template) MarshalJSON() ([]byte, error) {
+	switch {
+	case t.kind == kindString && t.ID == 0:
+		return json.Marshal(t.Name)
+	case t.kind == kindObject || len(t.Name) > 0:
+		obj := copyKeys(t.extra)
+		if err := putKey(obj, "id", t.ID, t.ID != 0); err != nil {
+			return nil, err
+		}
+		if err := putKey(obj, "name", t.Name, len(t.Name) > 0); err != nil {
+			return nil, err
+		}
+		return json.Marshal(obj)
+	}
+	return json.Marshal(t.ID)
+}
+
+// readKey decodes key from obj, missing keys and null are ignored
+func readKey(obj map[string]json.RawMessage, key string, v interface{}) error {
+	raw, ok := obj[key]
+	if !ok || string(raw) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%s: %s", key, err)
+	}
+	return nil
+}
+
+// putKey writes v to key when obj already has the key or set is true. A
+// null key is left alone while v is unset.
+func putKey(obj map[string]json.RawMessage, key string, v interface{}, set bool) error {
+	raw, ok := obj[key]
+	if !ok && !set || ok && !set && string(raw) == "null" {
+		return nil
+	}
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	obj[key] = bs
+	return nil
+}
+
+func copyKeys(obj map[string]json.RawMessage) map[string]json.RawMessage {
+	cp := make(map[string]json.RawMessage, len(obj))
+	for k, v := range obj {
+		cp[k] = v
+	}
+	return cp
+}
@@ -0,0 +1,152 @@
+package makeplans
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNotification_roundTrip(t *testing.T) {
+	in := []byte(`{"day_booking_specify_time":true,"mail_confirmation":{"enabled":true,"layout":"fancy","subject":"Booked","text":"See you"},"mail_verification":"Please verify","sms_confirmation":false,"sms_reminder":{"minutes_before":120,"text":"Tomorrow"},"template":7}`)
+
+	var svc Service
+	err := json.Unmarshal(in, &svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if svc.DayBookingSpecifyTime == nil || !*svc.DayBookingSpecifyTime {
+		t.Error("expected day booking specify time")
+	}
+	if e := "Booked"; svc.MailConfirmation.Subject != e {
+		t.Errorf("got: %s wanted: %s", svc.MailConfirmation.Subject, e)
+	}
+	if e := "Please verify"; !svc.MailVerification.Enabled || svc.MailVerification.Text != e {
+		t.Errorf("got: %+v wanted: %s", svc.MailVerification, e)
+	}
+	if svc.SmsConfirmation.Enabled {
+		t.Error("expected sms confirmation disabled")
+	}
+	if e := 2 * time.Hour; svc.SmsReminder.Before != e {
+		t.Errorf("got: %s wanted: %s", svc.SmsReminder.Before, e)
+	}
+	if e := 7; svc.Template.ID != e {
+		t.Errorf("got: %d wanted: %d", svc.Template.ID, e)
+	}
+	if svc.SmsVerification != nil {
+		t.Error("expected default sms verification")
+	}
+
+	out, err := json.Marshal(svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != string(in) {
+		t.Errorf("got:\n%s\nwanted:\n%s", out, in)
+	}
+}
+
+func TestNotification_new(t *testing.T) {
+	svc := Service{
+		SmsVerification: NewNotification("Your code"),
+		SmsReminder: &Reminder{
+			Notification: Notification{Enabled: true},
+			Before:       24 * time.Hour,
+		},
+		Template: &Template{ID: 3},
+	}
+	out, err := json.Marshal(svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := `{"sms_reminder":{"enabled":true,"minutes_before":1440},"sms_verification":"Your code","template":3}`
+	if string(out) != e {
+		t.Errorf("got:\n%s\nwanted:\n%s", out, e)
+	}
+
+	// Disabling custom text no longer fits in a string
+	svc.SmsVerification.Enabled = false
+	out, err = json.Marshal(svc.SmsVerification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := `{"enabled":false,"text":"Your code"}`; string(out) != e {
+		t.Errorf("got: %s wanted: %s", out, e)
+	}
+}
+
+func TestNotification_fixtures(t *testing.T) {
+	// Settings are written back exactly as received
+	notifications := []string{
+		`true`,
+		`false`,
+		`"Please verify"`,
+		`{"enabled":false}`,
+		`{"text":"See you"}`,
+		`{"enabled":true,"layout":"fancy","subject":"Booked","text":"See you"}`,
+		`{"enabled":true,"subject":null,"text":""}`,
+	}
+	for _, in := range notifications {
+		var n Notification
+		if err := json.Unmarshal([]byte(in), &n); err != nil {
+			t.Fatal(err)
+		}
+		out, err := json.Marshal(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != in {
+			t.Errorf("got: %s wanted: %s", out, in)
+		}
+	}
+
+	reminders := []string{
+		`false`,
+		`"Tomorrow"`,
+		`{"minutes_before":0,"text":"Tomorrow"}`,
+		`{"enabled":false,"minutes_before":120}`,
+		`{"minutes_before":null}`,
+	}
+	for _, in := range reminders {
+		var r Reminder
+		if err := json.Unmarshal([]byte(in), &r); err != nil {
+			t.Fatal(err)
+		}
+		out, err := json.Marshal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != in {
+			t.Errorf("got: %s wanted: %s", out, in)
+		}
+	}
+
+	templates := []string{`7`, `"fancy"`, `{"id":0,"name":"fancy"}`, `{"id":7,"name":null}`}
+	for _, in := range templates {
+		var tmpl Template
+		if err := json.Unmarshal([]byte(in), &tmpl); err != nil {
+			t.Fatal(err)
+		}
+		out, err := json.Marshal(tmpl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != in {
+			t.Errorf("got: %s wanted: %s", out, in)
+		}
+	}
+
+	// Changes are laid over the keys received
+	var r Reminder
+	if err := json.Unmarshal([]byte(`{"enabled":false,"minutes_before":120}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	r.Before, r.Text = 0, "Soon"
+	out, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := `{"enabled":false,"minutes_before":0,"text":"Soon"}`; string(out) != e {
+		t.Errorf("got: %s wanted: %s", out, e)
+	}
+}
@@ -9,23 +9,23 @@ import (
 )
 
 type Person struct {
-	ID                int                    `json:"id,omitempty"`
-	CreatedAt         *time.Time             `json:"created_at,omitempty"`
-	UpdatedAt         *time.Time             `json:"updated_at,omitempty"`
-	Name              string                 `json:"name,omitempty"`
-	Email             string                 `json:"email,omitempty"`
-	PhoneNumber       string                 `json:"phonenumber,omitempty"`
-	PrettyPhoneNumber string                 `json:"phone_number_formatted,omitempty"`
-	ExternalID        string                 `json:"external_id,omitempty"`
-	CustomData        map[string]interface{} `json:"custom_data,omitempty"`
-	DOB               string                 `json:"date_of_birth,omitempty,omitempty"`
-	NationalID        string                 `json:"national_id_no,omitempty"`
-	Street            string                 `json:"street,omitempty"`
-	City              string                 `json:"city,omitempty"`
-	PostalCode        string                 `json:"postal_code,omitempty"`
-	State             string                 `json:"state,omitempty"`
-	CountryCode       string                 `json:"country_code,omitempty"`
-	Notes             string                 `json:"notes,omitempty"`
+	ID                int        `json:"id,omitempty"`
+	CreatedAt         *time.Time `json:"created_at,omitempty"`
+	UpdatedAt         *time.Time `json:"updated_at,omitempty"`
+	Name              string     `json:"name,omitempty"`
+	Email             string     `json:"email,omitempty"`
+	PhoneNumber       string     `json:"phonenumber,omitempty"`
+	PrettyPhoneNumber string     `json:"phone_number_formatted,omitempty"`
+	ExternalID        string     `json:"external_id,omitempty"`
+	CustomData        CustomData `json:"custom_data,omitempty"`
+	DOB               string     `json:"date_of_birth,omitempty,omitempty"`
+	NationalID        string     `json:"national_id_no,omitempty"`
+	Street            string     `json:"street,omitempty"`
+	City              string     `json:"city,omitempty"`
+	PostalCode        string     `json:"postal_code,omitempty"`
+	State             string     `json:"state,omitempty"`
+	CountryCode       string     `json:"country_code,omitempty"`
+	Notes             string     `json:"notes,omitempty"`
 }
 
 type personWrap struct {
@@ -53,6 +53,10 @@ func (c *Client) People() ([]Person, error) {
 }
 
 func (c *Client) MakePerson(p Person) (ret Person, err error) {
+	err = c.validateCustomData("person", p.CustomData)
+	if err != nil {
+		return
+	}
 	var buf bytes.Buffer
 	enc := json.NewEncoder(&buf)
 	err = enc.Encode(personWrap{p})
@@ -71,9 +75,13 @@ func (c *Client) MakePerson(p Person) (ret Person, err error) {
 }
 
 func (c *Client) UpdatePerson(p Person) (Person, error) {
+	err := c.validateCustomData("person", p.CustomData)
+	if err != nil {
+		return Person{}, err
+	}
 	var buf bytes.Buffer
 	enc := json.NewEncoder(&buf)
-	err := enc.Encode(personWrap{p})
+	err = enc.Encode(personWrap{p})
 	if err != nil {
 		return Person{}, err
 	}
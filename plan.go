@@ -0,0 +1,607 @@
+package makeplans
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// AccountConfig declares the services and resources of an account. Keys
+// are chosen by the user, a ConfigState maps them to Makeplans IDs.
+//
+//	services:
+//	  yoga: {title: Yoga, interval: 60, active: true}
+//	resources:
+//	  studio:
+//	    title: Studio
+//	    opening_hours_mon: ["08:00", "16:00"]
+//	    provides: [yoga]
+type AccountConfig struct {
+	Services  map[string]ConfigFields   `json:"services"`
+	Resources map[string]ResourceConfig `json:"resources"`
+}
+
+// ConfigFields are the declared fields of a record, named and typed as in
+// the API. Fields left out are not managed. The API omits empty values, so
+// a field can't be changed back to false, 0 or empty, ApplyConfig reports
+// it as not changed. Exceptions are active, which deactivates a service
+// through ServiceDelete, opening hours, [] closes the day, and price, null
+// removes it.
+type ConfigFields map[string]interface{}
+
+// ResourceConfig declares a resource and, when Provides is set, the keys
+// of the services it provides
+type ResourceConfig struct {
+	Fields   ConfigFields
+	Provides []string
+}
+
+func (rc *ResourceConfig) UnmarshalJSON(bs []byte) error {
+	var fields ConfigFields
+	if err := json.Unmarshal(bs, &fields); err != nil {
+		return err
+	}
+	if p, ok := fields["provides"]; ok {
+		delete(fields, "provides")
+		raw, _ := json.Marshal(p)
+		if err := json.Unmarshal(raw, &rc.Provides); err != nil {
+			return fmt.Errorf("provides: %s", err)
+		}
+		if rc.Provides == nil {
+			rc.Provides = []string{}
+		}
+	}
+	rc.Fields = fields
+	return nil
+}
+
+func (rc ResourceConfig) MarshalJSON() ([]byte, error) {
+	fields := ConfigFields{}
+	for k, v := range rc.Fields {
+		fields[k] = v
+	}
+	if rc.Provides != nil {
+		fields["provides"] = rc.Provides
+	}
+	return json.Marshal(fields)
+}
+
+// ConfigState maps the keys of an AccountConfig to Makeplans IDs
+type ConfigState struct {
+	Services  map[string]int `json:"services"`
+	Resources map[string]int `json:"resources"`
+}
+
+func (s ConfigState) copy() ConfigState {
+	c := ConfigState{Services: map[string]int{}, Resources: map[string]int{}}
+	for k, id := range s.Services {
+		c.Services[k] = id
+	}
+	for k, id := range s.Resources {
+		c.Resources[k] = id
+	}
+	return c
+}
+
+// ConfigAPI is the part of the API PlanConfig and ApplyConfig use
+type ConfigAPI interface {
+	ServiceService
+	ResourceService
+	ProviderService
+}
+
+// ConfigAction is what a ConfigChange does
+type ConfigAction string
+
+const (
+	ConfigCreate ConfigAction = "create"
+	ConfigUpdate ConfigAction = "update"
+	// ConfigAdopt records a matching record, found by title, in the state
+	ConfigAdopt ConfigAction = "adopt"
+	// ConfigRemove deletes a provider
+	ConfigRemove ConfigAction = "remove"
+	// ConfigForget drops a key removed from the config from the state. The
+	// record is left in the account.
+	ConfigForget ConfigAction = "forget"
+)
+
+// FieldChange is a field that differs between the account and the config
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// ConfigChange is one step of a ConfigPlan. Kind is service, resource or
+// provider, providers are keyed resource/service.
+type ConfigChange struct {
+	Action  ConfigAction  `json:"action"`
+	Kind    string        `json:"kind"`
+	Key     string        `json:"key"`
+	ID      int           `json:"id,omitempty"`
+	Changes []FieldChange `json:"changes,omitempty"`
+
+	service  Service
+	resource Resource
+	// fields are the declared fields as the model encodes them, checked
+	// against the record Makeplans returns
+	fields map[string]interface{}
+}
+
+func (c ConfigChange) String() string {
+	sign := map[ConfigAction]string{
+		ConfigCreate: "+", ConfigUpdate: "~", ConfigAdopt: "=",
+		ConfigRemove: "-", ConfigForget: "-",
+	}[c.Action]
+	s := fmt.Sprintf("%s %s %s %s", sign, c.Action, c.Kind, c.Key)
+	if c.ID > 0 {
+		s += fmt.Sprintf(" (%d)", c.ID)
+	}
+	for _, fc := range c.Changes {
+		if c.Action == ConfigCreate {
+			s += fmt.Sprintf("\n    %s: %s", fc.Field, showValue(fc.To))
+			continue
+		}
+		s += fmt.Sprintf("\n    %s: %s -> %s", fc.Field, showValue(fc.From), showValue(fc.To))
+	}
+	return s
+}
+
+func showValue(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	bs, _ := json.Marshal(v)
+	return string(bs)
+}
+
+// ConfigPlan is the list of changes converging an account to its config
+type ConfigPlan struct {
+	Changes []ConfigChange
+	// State is the state before apply, without the forgotten keys and with
+	// the adopted records
+	State ConfigState
+}
+
+func (p *ConfigPlan) String() string {
+	if len(p.Changes) == 0 {
+		return "No changes, the account matches the config."
+	}
+	lines := make([]string, len(p.Changes))
+	count := map[ConfigAction]int{}
+	for i, c := range p.Changes {
+		lines[i] = c.String()
+		count[c.Action]++
+	}
+	return fmt.Sprintf("%s\n\nPlan: %d to create, %d to update, %d to remove.",
+		strings.Join(lines, "\n"), count[ConfigCreate], count[ConfigUpdate], count[ConfigRemove])
+}
+
+// PlanConfig compares the account to cfg. Keys missing from state are
+// matched to an existing record by title before planning to create them,
+// so an account can be brought under management. Known services that are
+// inactive, so not listed, are updated when declared active and otherwise
+// left inactive, they are never created again. Providers of
+// resources with Provides set are planned with PlanProviders.
+func PlanConfig(api ConfigAPI, cfg AccountConfig, state ConfigState) (*ConfigPlan, error) {
+	services, err := api.Services()
+	if err != nil {
+		return nil, err
+	}
+	resources, err := api.Resources()
+	if err != nil {
+		return nil, err
+	}
+	providers, err := api.Providers()
+	if err != nil {
+		return nil, err
+	}
+	plan := &ConfigPlan{State: state.copy()}
+
+	liveServices := map[int]Service{}
+	for _, svc := range services {
+		liveServices[svc.ID] = svc
+	}
+	for _, key := range sortedKeys(plan.State.Services) {
+		if _, ok := cfg.Services[key]; !ok {
+			plan.forget("service", key, plan.State.Services)
+		}
+	}
+	for _, key := range sortedKeys(cfg.Services) {
+		fields, err := normalize(cfg.Services[key], reflect.TypeOf(Service{}))
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %s", key, err)
+		}
+		id, known := plan.State.Services[key]
+		live, ok := liveServices[id]
+		if !ok && !known {
+			live, ok = findByTitle(services, fields, plan.State.Services, func(s Service) (int, string) { return s.ID, s.Title })
+		}
+		c := ConfigChange{Kind: "service", Key: key}
+		switch {
+		case ok:
+			c.ID = live.ID
+			if c.fields, err = declared(live, fields, &c.service); err != nil {
+				return nil, fmt.Errorf("service %s: %s", key, err)
+			}
+			c.Changes = fieldChanges(live, c.fields)
+			plan.add(c, plan.State.Services)
+		case known && fields["active"] != true:
+			// Inactive services aren't listed, they stay inactive
+		case known:
+			// Reactivate the service. Inactive services can't be read, every
+			// declared field is set again.
+			inactive := map[string]interface{}{"id": id, "active": false}
+			c.ID = id
+			if c.fields, err = declared(inactive, fields, &c.service); err != nil {
+				return nil, fmt.Errorf("service %s: %s", key, err)
+			}
+			c.Changes = fieldChanges(inactive, c.fields)
+			plan.add(c, plan.State.Services)
+		default:
+			delete(plan.State.Services, key)
+			c.Action = ConfigCreate
+			if c.fields, err = declared(nil, fields, &c.service); err != nil {
+				return nil, fmt.Errorf("service %s: %s", key, err)
+			}
+			c.Changes = fieldChanges(nil, c.fields)
+			plan.Changes = append(plan.Changes, c)
+		}
+	}
+
+	liveResources := map[int]Resource{}
+	for _, r := range resources {
+		liveResources[r.ID] = r
+	}
+	for _, key := range sortedKeys(plan.State.Resources) {
+		if _, ok := cfg.Resources[key]; !ok {
+			plan.forget("resource", key, plan.State.Resources)
+		}
+	}
+	for _, key := range sortedKeys(cfg.Resources) {
+		rc := cfg.Resources[key]
+		for _, svc := range rc.Provides {
+			if _, ok := cfg.Services[svc]; !ok {
+				return nil, fmt.Errorf("resource %s: provides unknown service %s", key, svc)
+			}
+		}
+		fields, err := normalize(rc.Fields, reflect.TypeOf(Resource{}))
+		if err == nil {
+			err = validateHours(fields)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("resource %s: %s", key, err)
+		}
+		live, ok := liveResources[plan.State.Resources[key]]
+		if !ok {
+			live, ok = findByTitle(resources, fields, plan.State.Resources, func(r Resource) (int, string) { return r.ID, r.Title })
+		}
+		c := ConfigChange{Kind: "resource", Key: key}
+		if !ok {
+			delete(plan.State.Resources, key)
+			c.Action = ConfigCreate
+			if c.fields, err = declared(nil, fields, &c.resource); err != nil {
+				return nil, fmt.Errorf("resource %s: %s", key, err)
+			}
+			c.Changes = fieldChanges(nil, c.fields)
+			plan.Changes = append(plan.Changes, c)
+		} else {
+			c.ID = live.ID
+			// Services are managed through providers
+			live.Services = nil
+			if c.fields, err = declared(live, fields, &c.resource); err != nil {
+				return nil, fmt.Errorf("resource %s: %s", key, err)
+			}
+			c.Changes = fieldChanges(live, c.fields)
+			plan.add(c, plan.State.Resources)
+		}
+		if rc.Provides != nil {
+			plan.providers(key, c.ID, rc.Provides, providers)
+		}
+	}
+	return plan, nil
+}
+
+// add appends a change to an existing record, adopting it into the state
+// when the key is new
+func (p *ConfigPlan) add(c ConfigChange, ids map[string]int) {
+	_, known := ids[c.Key]
+	ids[c.Key] = c.ID
+	switch {
+	case len(c.Changes) > 0:
+		c.Action = ConfigUpdate
+	case !known:
+		c.Action = ConfigAdopt
+	default:
+		return
+	}
+	p.Changes = append(p.Changes, c)
+}
+
+func (p *ConfigPlan) forget(kind string, key string, ids map[string]int) {
+	p.Changes = append(p.Changes, ConfigChange{
+		Action: ConfigForget, Kind: kind, Key: key, ID: ids[key],
+	})
+	delete(ids, key)
+}
+
+// providers plans the providers of a resource. Services and resources
+// created by the plan don't have IDs yet, their providers are created.
+func (p *ConfigPlan) providers(resourceKey string, resourceID int, provides []string, current []Provider) {
+	serviceKeys := map[int]string{}
+	var known []int
+	var created []string
+	for _, key := range provides {
+		if id, ok := p.State.Services[key]; ok {
+			serviceKeys[id] = key
+			known = append(known, id)
+		} else {
+			created = append(created, key)
+		}
+	}
+	var create, remove []Provider
+	if resourceID > 0 {
+		create, remove = PlanProviders(current, resourceID, known)
+	} else {
+		for _, id := range known {
+			create = append(create, Provider{ServiceID: id})
+		}
+	}
+	for _, prov := range remove {
+		key := fmt.Sprintf("%s/%d", resourceKey, prov.ServiceID)
+		if k, ok := serviceKeys[prov.ServiceID]; ok {
+			// A duplicate of a declared provider
+			key = resourceKey + "/" + k
+		}
+		p.Changes = append(p.Changes, ConfigChange{
+			Action: ConfigRemove, Kind: "provider", Key: key, ID: prov.ID,
+		})
+	}
+	for _, prov := range create {
+		created = append(created, serviceKeys[prov.ServiceID])
+	}
+	for _, key := range created {
+		p.Changes = append(p.Changes, ConfigChange{
+			Action: ConfigCreate, Kind: "provider", Key: resourceKey + "/" + key,
+		})
+	}
+}
+
+// ApplyConfig makes the changes of a plan. Services and resources are
+// checked to hold the declared fields once saved. The returned state holds
+// the records created, on error it reflects the changes that succeeded and
+// should still be saved.
+func ApplyConfig(api ConfigAPI, plan *ConfigPlan) (ConfigState, error) {
+	state := plan.State.copy()
+	for _, c := range plan.Changes {
+		if err := applyChange(api, c, state); err != nil {
+			return state, fmt.Errorf("%s %s %s: %s", c.Action, c.Kind, c.Key, err)
+		}
+	}
+	return state, nil
+}
+
+func applyChange(api ConfigAPI, c ConfigChange, state ConfigState) error {
+	switch {
+	case c.Kind == "service" && c.Action == ConfigCreate:
+		svc, err := api.ServiceCreate(c.service)
+		if err != nil {
+			return err
+		}
+		state.Services[c.Key] = svc.ID
+		return unchanged(svc, c.fields)
+	case c.Kind == "service" && c.Action == ConfigUpdate:
+		// A nil price is omitted, a declared null removes it
+		price, ok := c.fields["price"]
+		c.service.ClearPrice = ok && price == nil
+		svc, err := api.ServiceSave(c.service)
+		if err != nil {
+			return err
+		}
+		// Active can't be sent as false, deactivate the service instead
+		for _, fc := range c.Changes {
+			if fc.Field == "active" && fc.To == false {
+				if svc, err = api.ServiceDelete(c.ID); err != nil {
+					return err
+				}
+			}
+		}
+		return unchanged(svc, c.fields)
+	case c.Kind == "resource" && c.Action == ConfigCreate:
+		r, err := api.MakeResource(c.resource)
+		if err != nil {
+			return err
+		}
+		state.Resources[c.Key] = r.ID
+		return unchanged(r, c.fields)
+	case c.Kind == "resource" && c.Action == ConfigUpdate:
+		r, err := api.ResourceUpdate(c.resource)
+		if err != nil {
+			return err
+		}
+		return unchanged(r, c.fields)
+	case c.Kind == "provider" && c.Action == ConfigCreate:
+		resource, service, _ := strings.Cut(c.Key, "/")
+		_, err := api.MakeProvider(Provider{
+			ResourceID: state.Resources[resource],
+			ServiceID:  state.Services[service],
+		})
+		return err
+	case c.Kind == "provider" && c.Action == ConfigRemove:
+		_, err := api.ProviderDelete(c.ID)
+		return err
+	}
+	return nil
+}
+
+// unchanged reports the declared fields the saved record doesn't hold,
+// ie. empty values the API dropped
+func unchanged(saved interface{}, fields map[string]interface{}) error {
+	current := map[string]interface{}{}
+	bs, _ := json.Marshal(saved)
+	json.Unmarshal(bs, &current)
+	var names []string
+	for _, name := range sortedKeys(fields) {
+		got, want := current[name], fields[name]
+		if !reflect.DeepEqual(got, want) && !(empty(got) && empty(want)) {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		return fmt.Errorf("%s not changed by Makeplans", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// normalize checks the fields against the model t and converts their
+// values to their JSON form
+func normalize(fields ConfigFields, t reflect.Type) (map[string]interface{}, error) {
+	known := jsonFields(t)
+	var norm map[string]interface{}
+	bs, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bs, &norm); err != nil {
+		return nil, err
+	}
+	for name := range norm {
+		switch name {
+		case "id", "created_at", "updated_at":
+			return nil, fmt.Errorf("%s can't be configured", name)
+		}
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("unknown field %s", name)
+		}
+	}
+	return norm, nil
+}
+
+// fieldChanges lists the declared fields that differ from the live
+// record, nil for a new record. Missing live fields are empty.
+func fieldChanges(live interface{}, fields map[string]interface{}) []FieldChange {
+	current := map[string]interface{}{}
+	if live != nil {
+		bs, _ := json.Marshal(live)
+		json.Unmarshal(bs, &current)
+	}
+	var changes []FieldChange
+	for _, name := range sortedKeys(fields) {
+		from, to := current[name], fields[name]
+		if reflect.DeepEqual(from, to) || from == nil && empty(to) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: name, From: from, To: to})
+	}
+	return changes
+}
+
+func empty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// declared decodes the fields on top of the live record into v and
+// returns the fields as v encodes them, so they compare with the API
+// values, ie. a price of "20.0" is "20.00". Fields the model leaves out
+// keep their declared, empty, value.
+func declared(live interface{}, fields map[string]interface{}, v interface{}) (map[string]interface{}, error) {
+	if err := decodeFields(live, fields, v); err != nil {
+		return nil, err
+	}
+	encoded := map[string]interface{}{}
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bs, &encoded); err != nil {
+		return nil, err
+	}
+	norm := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		if e, ok := encoded[name]; ok {
+			value = e
+		}
+		norm[name] = value
+	}
+	return norm, nil
+}
+
+// validateHours checks the declared opening hours like SetOpeningHours
+func validateHours(fields map[string]interface{}) error {
+	for _, name := range sortedKeys(fields) {
+		if !strings.HasPrefix(name, "opening_hours_") {
+			continue
+		}
+		var hours []string
+		bs, _ := json.Marshal(fields[name])
+		if err := json.Unmarshal(bs, &hours); err != nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+		if _, err := ParseTimeRanges(hours); err != nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// decodeFields sets the fields on top of the live record into v
+func decodeFields(live interface{}, fields map[string]interface{}, v interface{}) error {
+	merged := map[string]interface{}{}
+	if live != nil {
+		bs, _ := json.Marshal(live)
+		json.Unmarshal(bs, &merged)
+	}
+	for name, value := range fields {
+		merged[name] = value
+	}
+	bs, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bs, v)
+}
+
+// findByTitle finds the record with the declared title that isn't in the
+// state yet
+func findByTitle[T any](records []T, fields map[string]interface{}, state map[string]int, key func(T) (int, string)) (T, bool) {
+	var zero T
+	want, ok := fields["title"].(string)
+	if !ok || len(want) == 0 {
+		return zero, false
+	}
+	taken := map[int]bool{}
+	for _, id := range state {
+		taken[id] = true
+	}
+	for _, r := range records {
+		if id, title := key(r); title == want && !taken[id] {
+			return r, true
+		}
+	}
+	return zero, false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,271 @@
+package makeplans_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/drewwells/makeplans"
+	"github.com/drewwells/makeplans/makeplanstest"
+)
+
+func readAccountConfig(t *testing.T, s string) makeplans.AccountConfig {
+	t.Helper()
+	var cfg makeplans.AccountConfig
+	if err := json.Unmarshal([]byte(s), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// settled fails the test when planning cfg again has changes
+func settled(t *testing.T, api makeplans.ConfigAPI, cfg makeplans.AccountConfig, state makeplans.ConfigState) {
+	t.Helper()
+	plan, err := makeplans.PlanConfig(api, cfg, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) > 0 {
+		t.Errorf("got:\n%s\nwanted no changes", plan)
+	}
+}
+
+func TestPlanConfig(t *testing.T) {
+	srv := makeplanstest.NewServer()
+	defer srv.Close()
+	srv.AddService(makeplans.Service{ID: 393, Title: "Yoga", Interval: 30, Active: true})
+	srv.AddResource(makeplans.Resource{ID: 484, Title: "Studio", Capacity: 10,
+		OpeningHoursMon: []string{"08:00", "16:00"}})
+	srv.AddProvider(makeplans.Provider{ID: 11, ServiceID: 393, ResourceID: 484})
+	srv.AddProvider(makeplans.Provider{ID: 12, ServiceID: 395, ResourceID: 484})
+	client := srv.Client()
+
+	cfg := readAccountConfig(t, `{
+		"services": {
+			"yoga": {"title": "Yoga", "interval": 60, "active": true},
+			"spin": {"title": "Spin", "interval": 45, "active": true}
+		},
+		"resources": {
+			"studio": {
+				"title": "Studio",
+				"capacity": 10,
+				"opening_hours_mon": ["08:00", "16:00"],
+				"opening_hours_tue": ["09:00", "12:00"],
+				"provides": ["yoga", "spin"]
+			}
+		}
+	}`)
+
+	plan, err := makeplans.PlanConfig(client, cfg, makeplans.ConfigState{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := `+ create service spin
+    active: true
+    interval: 45
+    title: "Spin"
+~ update service yoga (393)
+    interval: 30 -> 60
+~ update resource studio (484)
+    opening_hours_tue: null -> ["09:00","12:00"]
+- remove provider studio/395 (12)
++ create provider studio/spin
+
+Plan: 2 to create, 2 to update, 1 to remove.`
+	if plan.String() != e {
+		t.Errorf("got:\n%s\nwanted:\n%s", plan, e)
+	}
+
+	state, err := makeplans.ApplyConfig(client, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spin := state.Services["spin"]
+	if len(state.Services) != 2 || state.Services["yoga"] != 393 || spin == 0 {
+		t.Errorf("got: %v wanted yoga and spin", state.Services)
+	}
+	if e := 484; state.Resources["studio"] != e {
+		t.Errorf("got: %d wanted: %d", state.Resources["studio"], e)
+	}
+	providers, err := client.Providers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(providers) != 2 || providers[0].ServiceID != spin || providers[0].ResourceID != 484 {
+		t.Errorf("got: %+v wanted spin at 484", providers)
+	}
+	settled(t, client, cfg, state)
+
+	// Deactivating spin and dropping the resource from the config
+	delete(cfg.Resources, "studio")
+	cfg.Services["spin"]["active"] = false
+	plan, err = makeplans.PlanConfig(client, cfg, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e = `~ update service spin (` + strconv.Itoa(spin) + `)
+    active: true -> false
+- forget resource studio (484)
+
+Plan: 0 to create, 1 to update, 0 to remove.`
+	if plan.String() != e {
+		t.Errorf("got:\n%s\nwanted:\n%s", plan, e)
+	}
+	if state, err = makeplans.ApplyConfig(client, plan); err != nil {
+		t.Fatal(err)
+	}
+	svcs, err := client.Services()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(svcs) != 1 || svcs[0].ID != 393 {
+		t.Errorf("got: %+v wanted spin inactive", svcs)
+	}
+	settled(t, client, cfg, state)
+
+	// Activating spin again keeps its ID
+	cfg.Services["spin"]["active"] = true
+	plan, err = makeplans.PlanConfig(client, cfg, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e = `~ update service spin (` + strconv.Itoa(spin) + `)
+    active: false -> true
+    interval: null -> 45
+    title: null -> "Spin"
+
+Plan: 0 to create, 1 to update, 0 to remove.`
+	if plan.String() != e {
+		t.Errorf("got:\n%s\nwanted:\n%s", plan, e)
+	}
+	if state, err = makeplans.ApplyConfig(client, plan); err != nil {
+		t.Fatal(err)
+	}
+	if e := spin; state.Services["spin"] != e {
+		t.Errorf("got: %d wanted: %d", state.Services["spin"], e)
+	}
+	settled(t, client, cfg, state)
+}
+
+func TestPlanConfig_inactive(t *testing.T) {
+	srv := makeplanstest.NewServer()
+	defer srv.Close()
+	srv.AddService(makeplans.Service{ID: 393, Title: "Yoga", Interval: 60})
+	client := srv.Client()
+
+	// An inactive service isn't listed, without active it stays inactive
+	cfg := readAccountConfig(t, `{"services": {"yoga": {"title": "Yoga", "interval": 60}}}`)
+	state := makeplans.ConfigState{Services: map[string]int{"yoga": 393}}
+	plan, err := makeplans.PlanConfig(client, cfg, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) > 0 {
+		t.Errorf("got:\n%s\nwanted no changes", plan)
+	}
+	if e := 393; plan.State.Services["yoga"] != e {
+		t.Errorf("got: %d wanted: %d", plan.State.Services["yoga"], e)
+	}
+
+	cfg.Services["yoga"]["active"] = true
+	if plan, err = makeplans.PlanConfig(client, cfg, state); err != nil {
+		t.Fatal(err)
+	}
+	e := `~ update service yoga (393)
+    active: false -> true
+    interval: null -> 60
+    title: null -> "Yoga"
+
+Plan: 0 to create, 1 to update, 0 to remove.`
+	if plan.String() != e {
+		t.Errorf("got:\n%s\nwanted:\n%s", plan, e)
+	}
+}
+
+func TestPlanConfig_normalized(t *testing.T) {
+	srv := makeplanstest.NewServer()
+	defer srv.Close()
+	price := makeplans.Money{Amount: 2000}
+	srv.AddService(makeplans.Service{ID: 393, Title: "Yoga", Interval: 60,
+		Active: true, Price: &price})
+	srv.AddResource(makeplans.Resource{ID: 484, Title: "Studio", Capacity: 10,
+		OpeningHoursSat: []string{"10:00", "12:00"}})
+	client := srv.Client()
+
+	// Makeplans sends "20.0" and closes days with null
+	cfg := readAccountConfig(t, `{
+		"services": {"yoga": {"title": "Yoga", "price": "20.0"}},
+		"resources": {"studio": {"title": "Studio", "opening_hours_sat": []}}
+	}`)
+	plan, err := makeplans.PlanConfig(client, cfg, makeplans.ConfigState{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := `= adopt service yoga (393)
+~ update resource studio (484)
+    opening_hours_sat: ["10:00","12:00"] -> null
+
+Plan: 0 to create, 1 to update, 0 to remove.`
+	if plan.String() != e {
+		t.Errorf("got:\n%s\nwanted:\n%s", plan, e)
+	}
+	state, err := makeplans.ApplyConfig(client, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := client.Resource(484)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.OpeningHoursSat != nil {
+		t.Errorf("got: %v wanted closed", r.OpeningHoursSat)
+	}
+	settled(t, client, cfg, state)
+
+	// A null price removes the price
+	cfg.Services["yoga"]["price"] = nil
+	if plan, err = makeplans.PlanConfig(client, cfg, state); err != nil {
+		t.Fatal(err)
+	}
+	e = `~ update service yoga (393)
+    price: "20.00" -> null
+
+Plan: 0 to create, 1 to update, 0 to remove.`
+	if plan.String() != e {
+		t.Errorf("got:\n%s\nwanted:\n%s", plan, e)
+	}
+	if state, err = makeplans.ApplyConfig(client, plan); err != nil {
+		t.Fatal(err)
+	}
+	settled(t, client, cfg, state)
+
+	// A capacity of 0 is omitted and never reaches Makeplans
+	cfg.Resources["studio"].Fields["capacity"] = 0
+	plan, err = makeplans.PlanConfig(client, cfg, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = makeplans.ApplyConfig(client, plan)
+	if e := "update resource studio: capacity not changed by Makeplans"; err == nil || err.Error() != e {
+		t.Errorf("got: %v wanted: %s", err, e)
+	}
+}
+
+func TestPlanConfig_errors(t *testing.T) {
+	srv := makeplanstest.NewServer()
+	defer srv.Close()
+	for cfg, e := range map[string]string{
+		`{"services": {"yoga": {"intervall": 60}}}`:                                     "service yoga: unknown field intervall",
+		`{"services": {"yoga": {"interval": "an hour"}}}`:                               "service yoga: json: cannot unmarshal string into Go struct field Service.interval of type int",
+		`{"resources": {"studio": {"id": 484}}}`:                                        "resource studio: id can't be configured",
+		`{"resources": {"studio": {"title": "S", "provides": ["yoga"]}}}`:               "resource studio: provides unknown service yoga",
+		`{"resources": {"studio": {"opening_hours_mon": ["16:00", "08:00"]}}}`:          "resource studio: opening_hours_mon: opening hours 16:00-08:00 close before they open",
+		`{"resources": {"studio": {"opening_hours_tue": ["08:00", "12:00", "13:00"]}}}`: "resource studio: opening_hours_tue: opening hours must be pairs, got 3 values",
+	} {
+		_, err := makeplans.PlanConfig(srv.Client(), readAccountConfig(t, cfg), makeplans.ConfigState{})
+		if err == nil || !strings.HasPrefix(err.Error(), e) {
+			t.Errorf("got: %v wanted: %s", err, e)
+		}
+	}
+}
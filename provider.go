@@ -41,8 +41,8 @@ type Provider struct {
 	ID         int        `json:"id,omitempty"`
 	ServiceID  int        `json:"service_id,omitempty"`
 	ResourceID int        `json:"resource_id,omitempty"`
-	CreatedAt  *time.Time `json:"updated_at,omitempty"`
-	UpdatedAt  *time.Time `json:"created_at,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
 }
 
 type providerWrap struct {
@@ -116,3 +116,61 @@ func (c *Client) ProviderDelete(id int) (p Provider, err error) {
 	p = pw.Provider
 	return
 }
+
+// PlanProviders diffs the current providers of a resource against the
+// services it should provide. Providers for other resources are ignored.
+// Duplicate providers of a service are removed.
+func PlanProviders(current []Provider, resourceID int, serviceIDs []int) (create []Provider, remove []Provider) {
+	want := map[int]bool{}
+	for _, id := range serviceIDs {
+		want[id] = true
+	}
+	have := map[int]bool{}
+	for _, p := range current {
+		if p.ResourceID != resourceID {
+			continue
+		}
+		if !want[p.ServiceID] || have[p.ServiceID] {
+			remove = append(remove, p)
+			continue
+		}
+		have[p.ServiceID] = true
+	}
+	for _, id := range serviceIDs {
+		if have[id] {
+			continue
+		}
+		have[id] = true
+		create = append(create, Provider{ResourceID: resourceID, ServiceID: id})
+	}
+	return
+}
+
+// SyncProviders makes the resource provide exactly the services passed,
+// issuing the minimal MakeProvider and ProviderDelete calls. The providers
+// created and deleted are returned, on error they hold the calls that
+// succeeded.
+func (c *Client) SyncProviders(resourceID int, serviceIDs []int) (created []Provider, deleted []Provider, err error) {
+	current, err := c.Providers()
+	if err != nil {
+		return
+	}
+	create, remove := PlanProviders(current, resourceID, serviceIDs)
+	for _, p := range remove {
+		var del Provider
+		del, err = c.ProviderDelete(p.ID)
+		if err != nil {
+			return
+		}
+		deleted = append(deleted, del)
+	}
+	for _, p := range create {
+		var made Provider
+		made, err = c.MakeProvider(p)
+		if err != nil {
+			return
+		}
+		created = append(created, made)
+	}
+	return
+}
@@ -1,8 +1,10 @@
 package makeplans
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 )
 
 var testProviders = []byte(`[
@@ -62,6 +64,15 @@ func TestProvider_list(t *testing.T) {
 		t.Fatal("got zero creation or update time")
 	}
 
+	created, _ := time.Parse(time.RFC3339, "2015-11-03T04:07:39-06:00")
+	var p providerWrap
+	err = json.Unmarshal([]byte(`{"provider":{"created_at":"2015-11-03T04:07:39-06:00","updated_at":"2015-11-04T04:07:39-06:00"}}`), &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Provider.CreatedAt.Equal(created) {
+		t.Errorf("got: %s wanted: %s", p.Provider.CreatedAt, created)
+	}
 }
 
 var testProviderCreate = []byte(`{"provider":{"created_at":"2015-11-03T04:07:39-06:00","id":2044912816,"resource_id":503,"service_id":394,"updated_at":"2015-11-03T04:07:39-06:00"}}`)
@@ -130,3 +141,49 @@ func TestProvider_delete(t *testing.T) {
 		t.Errorf("got: %d wanted: %d", p.ServiceID, e)
 	}
 }
+
+var testProviderDelete395 = []byte(`{"provider":{"created_at":"2015-08-27T00:55:03-05:00","id":2044912747,"resource_id":484,"service_id":395,"updated_at":"2015-08-27T00:55:03-05:00"}}`)
+
+func TestProvider_plan(t *testing.T) {
+	current := []Provider{
+		{ID: 1, ResourceID: 484, ServiceID: 393},
+		{ID: 2, ResourceID: 484, ServiceID: 394},
+		{ID: 3, ResourceID: 484, ServiceID: 394},
+		{ID: 4, ResourceID: 484, ServiceID: 395},
+		{ID: 5, ResourceID: 503, ServiceID: 395},
+	}
+	create, remove := PlanProviders(current, 484, []int{393, 394, 399})
+
+	if e := 1; len(create) != e {
+		t.Fatalf("got: %d wanted: %d", len(create), e)
+	}
+	if e := (Provider{ResourceID: 484, ServiceID: 399}); create[0] != e {
+		t.Errorf("got: %v wanted: %v", create[0], e)
+	}
+
+	if e := 2; len(remove) != e {
+		t.Fatalf("got: %d wanted: %d", len(remove), e)
+	}
+	if remove[0].ID != 3 || remove[1].ID != 4 {
+		t.Errorf("got: %v wanted ids 3 and 4", remove)
+	}
+}
+
+func TestProvider_sync(t *testing.T) {
+	_, client := mockServerClient(t)
+	created, deleted, err := client.SyncProviders(484, []int{393, 394, 399})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e := 1; len(created) != e {
+		t.Fatalf("got: %d wanted: %d", len(created), e)
+	}
+
+	if e := 1; len(deleted) != e {
+		t.Fatalf("got: %d wanted: %d", len(deleted), e)
+	}
+	if e := 395; deleted[0].ServiceID != e {
+		t.Errorf("got: %d wanted: %d", deleted[0].ServiceID, e)
+	}
+}
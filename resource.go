@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -39,13 +40,40 @@ type Resource struct {
 	OpeningHoursSat []string `json:"opening_hours_sat,omitempty"`
 	OpeningHoursSun []string `json:"opening_hours_sun,omitempty"`
 
-	Services   []Service   `json:"services,omitempty"`
-	CustomData interface{} `json:"custom_data,omitempty"`
+	Services   []Service  `json:"services,omitempty"`
+	CustomData CustomData `json:"custom_data,omitempty"`
 
 	CreatedAt *time.Time `json:"created_at,omitempty"`
 	UpdatedAt *time.Time `json:"updated_at,omitempty"`
 }
 
+// MarshalJSON sends days with empty, not nil, opening hours as null. The
+// API omits them otherwise and Makeplans keeps the day open.
+func (r Resource) MarshalJSON() ([]byte, error) {
+	type resource Resource
+	bs, err := json.Marshal(resource(r))
+	if err != nil {
+		return nil, err
+	}
+	var closed []string
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if hours := *r.openingHoursDay(day); hours != nil && len(hours) == 0 {
+			closed = append(closed, "opening_hours_"+strings.ToLower(day.String()[:3]))
+		}
+	}
+	if len(closed) == 0 {
+		return bs, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(bs, &fields); err != nil {
+		return nil, err
+	}
+	for _, name := range closed {
+		fields[name] = json.RawMessage("null")
+	}
+	return json.Marshal(fields)
+}
+
 type resourceWrap struct {
 	Resource Resource `json:"resource"`
 }
@@ -102,6 +130,10 @@ func (c *Client) ResourceUpdate(r Resource) (Resource, error) {
 	if r.ID == 0 {
 		return ret.Resource, errors.New("id required")
 	}
+	err := c.validateCustomData("resource", r.CustomData)
+	if err != nil {
+		return ret.Resource, err
+	}
 	var buf bytes.Buffer
 	req := resourceWrap{
 		Resource: r,
@@ -129,9 +161,13 @@ func (c *Client) ResourceDelete(id int) (r Resource, err error) {
 }
 
 func (c *Client) MakeResource(r Resource) (Resource, error) {
+	err := c.validateCustomData("resource", r.CustomData)
+	if err != nil {
+		return Resource{}, err
+	}
 	var buf bytes.Buffer
 	enc := json.NewEncoder(&buf)
-	err := enc.Encode(resourceWrap{r})
+	err = enc.Encode(resourceWrap{r})
 	if err != nil {
 		return Resource{}, err
 	}
@@ -1,6 +1,9 @@
 package makeplans
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -187,3 +190,39 @@ func TestResource_opening(t *testing.T) {
 		t.Errorf("got: %d wanted: %d", len(rsc.Services), e)
 	}
 }
+
+func TestResource_closeDays(t *testing.T) {
+	var body map[string]map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{"resource":{"id":1}}`))
+	}))
+	defer ts.Close()
+	client := &Client{URL: ts.URL, Resolver: testResolver}
+
+	res := Resource{ID: 1, OpeningHoursMon: []string{"08:00", "16:00"}}
+	if err := res.SetOpeningHours(OpeningHours{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ResourceUpdate(res); err != nil {
+		t.Fatal(err)
+	}
+	got := body["resource"]
+	for _, day := range []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"} {
+		v, ok := got["opening_hours_"+day]
+		if !ok || v != nil {
+			t.Errorf("%s got: %v, %t wanted: null", day, v, ok)
+		}
+	}
+
+	// Days left alone are still omitted
+	bs, err := json.Marshal(Resource{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := `{"id":1}`; string(bs) != e {
+		t.Errorf("got: %s wanted: %s", bs, e)
+	}
+}
@@ -0,0 +1,155 @@
+package makeplans
+
+import "fmt"
+
+// RestoreAPI is the part of the API Restore writes to
+type RestoreAPI interface {
+	ServiceService
+	ResourceService
+	ProviderService
+	PersonService
+}
+
+// IDMap maps the IDs of a snapshot to the IDs of the records recreated
+// from them, by kind
+type IDMap struct {
+	Services  map[int]int `json:"services"`
+	Resources map[int]int `json:"resources"`
+	Providers map[int]int `json:"providers"`
+	People    map[int]int `json:"people"`
+}
+
+// NewIDMap returns an empty IDMap
+func NewIDMap() IDMap {
+	return IDMap{
+		Services:  map[int]int{},
+		Resources: map[int]int{},
+		Providers: map[int]int{},
+		People:    map[int]int{},
+	}
+}
+
+// RestoreFailure is a record of the snapshot that couldn't be recreated
+type RestoreFailure struct {
+	Kind  string `json:"kind"`
+	ID    int    `json:"id"`
+	Title string `json:"title,omitempty"`
+	Error string `json:"error"`
+}
+
+func (f RestoreFailure) String() string {
+	s := fmt.Sprintf("%s %d", f.Kind, f.ID)
+	if len(f.Title) > 0 {
+		s += fmt.Sprintf(" %q", f.Title)
+	}
+	return s + ": " + f.Error
+}
+
+// RestoreReport is the outcome of Restore
+type RestoreReport struct {
+	IDs      IDMap            `json:"ids"`
+	Created  SnapshotCounts   `json:"created"`
+	Failures []RestoreFailure `json:"failures,omitempty"`
+}
+
+// Restore recreates the services, resources, providers and people of a
+// snapshot in the account of api, ie. to copy a test account to production
+// or to set up a new location from an existing one. Events and bookings
+// are not restored.
+//
+// Templates belong to the source account, restored services use the
+// default template of the account.
+//
+// ids maps the records restored earlier, they are skipped so a restore
+// can be continued after fixing failures. The returned report holds ids
+// with the new records added. A record that fails doesn't stop the
+// restore, providers of a service or resource that failed fail as well.
+func Restore(api RestoreAPI, s *Snapshot, ids IDMap) *RestoreReport {
+	rep := &RestoreReport{IDs: NewIDMap()}
+	for _, m := range [][2]map[int]int{
+		{rep.IDs.Services, ids.Services},
+		{rep.IDs.Resources, ids.Resources},
+		{rep.IDs.Providers, ids.Providers},
+		{rep.IDs.People, ids.People},
+	} {
+		for from, to := range m[1] {
+			m[0][from] = to
+		}
+	}
+	fail := func(kind string, id int, title string, err error) {
+		rep.Failures = append(rep.Failures, RestoreFailure{
+			Kind: kind, ID: id, Title: title, Error: err.Error(),
+		})
+	}
+
+	for _, svc := range s.Services {
+		if _, ok := rep.IDs.Services[svc.ID]; ok {
+			continue
+		}
+		id := svc.ID
+		svc.ID, svc.CreatedAt, svc.UpdatedAt, svc.Template = 0, nil, nil, nil
+		created, err := api.ServiceCreate(svc)
+		if err != nil {
+			fail("service", id, svc.Title, err)
+			continue
+		}
+		rep.IDs.Services[id] = created.ID
+		rep.Created.Services++
+	}
+
+	for _, r := range s.Resources {
+		if _, ok := rep.IDs.Resources[r.ID]; ok {
+			continue
+		}
+		id := r.ID
+		// Services are linked through providers
+		r.ID, r.CreatedAt, r.UpdatedAt, r.Services = 0, nil, nil, nil
+		created, err := api.MakeResource(r)
+		if err != nil {
+			fail("resource", id, r.Title, err)
+			continue
+		}
+		rep.IDs.Resources[id] = created.ID
+		rep.Created.Resources++
+	}
+
+	for _, p := range s.Providers {
+		if _, ok := rep.IDs.Providers[p.ID]; ok {
+			continue
+		}
+		svc, ok := rep.IDs.Services[p.ServiceID]
+		if !ok {
+			fail("provider", p.ID, "", fmt.Errorf("service %d was not restored", p.ServiceID))
+			continue
+		}
+		res, ok := rep.IDs.Resources[p.ResourceID]
+		if !ok {
+			fail("provider", p.ID, "", fmt.Errorf("resource %d was not restored", p.ResourceID))
+			continue
+		}
+		created, err := api.MakeProvider(Provider{ServiceID: svc, ResourceID: res})
+		if err != nil {
+			fail("provider", p.ID, "", err)
+			continue
+		}
+		rep.IDs.Providers[p.ID] = created.ID
+		rep.Created.Providers++
+	}
+
+	for _, p := range s.People {
+		if _, ok := rep.IDs.People[p.ID]; ok {
+			continue
+		}
+		id := p.ID
+		p.ID, p.CreatedAt, p.UpdatedAt, p.PrettyPhoneNumber = 0, nil, nil, ""
+		created, err := api.MakePerson(p)
+		if err != nil {
+			fail("person", id, p.Name, err)
+			continue
+		}
+		rep.IDs.People[id] = created.ID
+		rep.Created.People++
+	}
+
+	return rep
+}
@@ -0,0 +1,93 @@
+package makeplans_test
+
+import (
+	"testing"
+
+	"github.com/drewwells/makeplans"
+	"github.com/drewwells/makeplans/makeplanstest"
+)
+
+func TestRestore(t *testing.T) {
+	s := &makeplans.Snapshot{
+		Services: []makeplans.Service{
+			{ID: 393, Title: "Yoga", Active: true, Interval: 60,
+				Template: &makeplans.Template{ID: 3}},
+			{ID: 394, Active: true, Interval: 30},
+		},
+		Resources: []makeplans.Resource{{ID: 484, Title: "Studio",
+			OpeningHoursMon: []string{"08:00", "16:00"},
+			Services:        []makeplans.Service{{ID: 393}}}},
+		Providers: []makeplans.Provider{
+			{ID: 1, ServiceID: 393, ResourceID: 484},
+			{ID: 2, ServiceID: 394, ResourceID: 484},
+		},
+		People: []makeplans.Person{
+			{ID: 7, Name: "Jane", PrettyPhoneNumber: "+1 555 0100"},
+			{ID: 8, Name: "Bob", Email: "taken@example.com"},
+		},
+	}
+	srv := makeplanstest.NewServer()
+	defer srv.Close()
+	srv.AddPerson(makeplans.Person{Name: "Robert", Email: "taken@example.com"})
+	client := srv.Client()
+
+	rep := makeplans.Restore(client, s, makeplans.IDMap{People: map[int]int{7: 77}})
+
+	e := []makeplans.RestoreFailure{
+		{Kind: "service", ID: 394, Error: "error title: can't be blank"},
+		{Kind: "provider", ID: 2, Error: "service 394 was not restored"},
+		{Kind: "person", ID: 8, Title: "Bob", Error: "error email: has already been taken"},
+	}
+	if len(rep.Failures) != len(e) {
+		t.Fatalf("got: %+v wanted: %+v", rep.Failures, e)
+	}
+	for i := range e {
+		if rep.Failures[i] != e[i] {
+			t.Errorf("%d got: %+v wanted: %+v", i, rep.Failures[i], e[i])
+		}
+	}
+	if e := `person 8 "Bob": error email: has already been taken`; rep.Failures[2].String() != e {
+		t.Errorf("got: %s wanted: %s", rep.Failures[2], e)
+	}
+	if e := (makeplans.SnapshotCounts{Services: 1, Resources: 1, Providers: 1}); rep.Created != e {
+		t.Errorf("got: %+v wanted: %+v", rep.Created, e)
+	}
+	if e := 77; rep.IDs.People[7] != e {
+		t.Errorf("got: %d wanted: %d", rep.IDs.People[7], e)
+	}
+
+	svcs, err := client.Services()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(svcs) != 1 || svcs[0].ID != rep.IDs.Services[393] {
+		t.Fatalf("got: %+v wanted service %d", svcs, rep.IDs.Services[393])
+	}
+	// The template of the source account doesn't exist here
+	if svcs[0].Template != nil {
+		t.Errorf("got: %+v wanted no template", svcs[0].Template)
+	}
+	r, err := client.Resource(rep.IDs.Resources[484])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 2; len(r.OpeningHoursMon) != e {
+		t.Errorf("got: %d wanted: %d", len(r.OpeningHoursMon), e)
+	}
+	providers, err := client.Providers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(providers) != 1 || providers[0].ServiceID != svcs[0].ID || providers[0].ResourceID != r.ID {
+		t.Errorf("got: %+v wanted service %d at resource %d", providers, svcs[0].ID, r.ID)
+	}
+
+	// Continuing skips what was restored and retries the failures
+	rep = makeplans.Restore(client, s, rep.IDs)
+	if e := 3; len(rep.Failures) != e {
+		t.Errorf("got: %+v", rep.Failures)
+	}
+	if e := (makeplans.SnapshotCounts{}); rep.Created != e {
+		t.Errorf("got: %+v wanted: %+v", rep.Created, e)
+	}
+}
@@ -11,27 +11,46 @@ import (
 var ServiceURL = "/services"
 
 type Service struct {
-	ID                    int         `json:"id,omitempty"`
-	Active                bool        `json:"active,omitempty"`
-	BookingCapacity       int         `json:"booking_capacity,omitempty"`
-	BookingTypeID         int         `json:"booking_type_id,omitempty"`
-	CustomData            interface{} `json:"custom_data,omitempty"`
-	DayBookingSpecifyTime interface{} `json:"day_booking_specify_time,omitempty"`
-	Description           string      `json:"description,omitempty"`
-	HasDayBooking         bool        `json:"has_day_booking,omitempty"`
-	Interval              int         `json:"interval,omitempty"`
-	MailConfirmation      interface{} `json:"mail_confirmation,omitempty"`
-	MailVerification      interface{} `json:"mail_verification,omitempty"`
-	MaxSlots              int         `json:"max_slots,omitempty"`
-	Price                 string      `json:"price,omitempty"`
-	SameDay               bool        `json:"same_day,omitempty"`
-	SmsConfirmation       interface{} `json:"sms_confirmation,omitempty"`
-	SmsReminder           interface{} `json:"sms_reminder,omitempty"`
-	SmsVerification       interface{} `json:"sms_verification,omitempty"`
-	Template              interface{} `json:"template,omitempty"`
-	Title                 string      `json:"title,omitempty"`
-	CreatedAt             *time.Time  `json:"created_at,omitempty"`
-	UpdatedAt             *time.Time  `json:"updated_at,omitempty"`
+	ID                    int           `json:"id,omitempty"`
+	Active                bool          `json:"active,omitempty"`
+	BookingCapacity       int           `json:"booking_capacity,omitempty"`
+	BookingTypeID         int           `json:"booking_type_id,omitempty"`
+	CustomData            CustomData    `json:"custom_data,omitempty"`
+	DayBookingSpecifyTime *bool         `json:"day_booking_specify_time,omitempty"`
+	Description           string        `json:"description,omitempty"`
+	HasDayBooking         bool          `json:"has_day_booking,omitempty"`
+	Interval              int           `json:"interval,omitempty"`
+	MailConfirmation      *Notification `json:"mail_confirmation,omitempty"`
+	MailVerification      *Notification `json:"mail_verification,omitempty"`
+	MaxSlots              int           `json:"max_slots,omitempty"`
+	Price                 *Money        `json:"price,omitempty"`
+	SameDay               bool          `json:"same_day,omitempty"`
+	SmsConfirmation       *Notification `json:"sms_confirmation,omitempty"`
+	SmsReminder           *Reminder     `json:"sms_reminder,omitempty"`
+	SmsVerification       *Notification `json:"sms_verification,omitempty"`
+	Template              *Template     `json:"template,omitempty"`
+	Title                 string        `json:"title,omitempty"`
+	CreatedAt             *time.Time    `json:"created_at,omitempty"`
+	UpdatedAt             *time.Time    `json:"updated_at,omitempty"`
+
+	// ClearPrice sends price as null to remove the price. A nil Price is
+	// omitted and Makeplans keeps the current price.
+	ClearPrice bool `json:"-"`
+}
+
+// MarshalJSON sends price as null when ClearPrice is set
+func (s Service) MarshalJSON() ([]byte, error) {
+	type service Service
+	bs, err := json.Marshal(service(s))
+	if err != nil || !s.ClearPrice {
+		return bs, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(bs, &fields); err != nil {
+		return nil, err
+	}
+	fields["price"] = json.RawMessage("null")
+	return json.Marshal(fields)
 }
 
 type serviceWrap struct {
@@ -50,12 +69,24 @@ func (c *Client) Services() ([]Service, error) {
 	// Assign to a proper struct
 	svcs := make([]Service, len(wr))
 	for i, w := range wr {
-		svcs[i] = w.Service
+		svcs[i] = c.priced(w.Service)
 	}
 	return svcs, err
 }
 
+// priced applies the account currency to the price of the service
+func (c *Client) priced(svc Service) Service {
+	if svc.Price != nil {
+		svc.Price.Currency = c.Currency
+	}
+	return svc
+}
+
 func (c *Client) ServiceSave(svc Service) (ret Service, err error) {
+	err = c.validateCustomData("service", svc.CustomData)
+	if err != nil {
+		return
+	}
 	id := strconv.Itoa(svc.ID)
 	payload, err := json.Marshal(serviceWrap{Service: svc})
 	if err != nil {
@@ -65,13 +96,17 @@ func (c *Client) ServiceSave(svc Service) (ret Service, err error) {
 	bs, err := c.Do("PUT", ServiceURL+"/"+id, buf)
 	var wrap serviceWrap
 	err = json.Unmarshal(bs, &wrap)
-	ret = wrap.Service
+	ret = c.priced(wrap.Service)
 	return
 }
 
 // ServiceCreate creates a new service. Not all fields are required, but
 // errors are thrown if required fields are missing
 func (c *Client) ServiceCreate(new Service) (svc Service, err error) {
+	err = c.validateCustomData("service", new.CustomData)
+	if err != nil {
+		return
+	}
 	var buf bytes.Buffer
 	enc := json.NewEncoder(&buf)
 	err = enc.Encode(serviceWrap{new})
@@ -97,7 +132,7 @@ func (c *Client) ServiceCreate(new Service) (svc Service, err error) {
 		err = fe
 		return
 	}
-	svc = wrap.Service
+	svc = c.priced(wrap.Service)
 	return
 }
 
@@ -115,6 +150,6 @@ func (c *Client) ServiceDelete(id int) (svc Service, err error) {
 		err = errors.New(string(resp))
 		return
 	}
-	svc = wrap.Service
+	svc = c.priced(wrap.Service)
 	return
 }
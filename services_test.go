@@ -1,6 +1,9 @@
 package makeplans
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+)
 
 var mockServiceWrapCreate = serviceWrap{
 	Service: Service{
@@ -8,7 +11,7 @@ var mockServiceWrapCreate = serviceWrap{
 		BookingCapacity: 1,
 		Interval:        30,
 		MaxSlots:        100,
-		Price:           "115.0",
+		Price:           &Money{Amount: 11500},
 		SameDay:         true,
 		Title:           "Chiropractor",
 		BookingTypeID:   1,
@@ -61,12 +64,34 @@ func TestService_list(t *testing.T) {
 			len(svcs), e)
 	}
 
-	if e := "20.0"; svcs[0].Price != e {
+	if e := "20.00"; svcs[0].Price.Decimal() != e {
 		t.Fatalf("got: %s wanted: %s", svcs[0].Price, e)
 	}
 
+	if svcs[1].Price != nil {
+		t.Errorf("expected nil price got: %s", svcs[1].Price)
+	}
+
 	if e := "Cross Fit Session"; e != svcs[0].Title {
 		t.Fatalf("got: %s wanted: %s", svcs[0].Title, e)
 	}
 
 }
+
+func TestService_clearPrice(t *testing.T) {
+	bs, err := json.Marshal(Service{ID: 393, ClearPrice: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := `{"id":393,"price":null}`; string(bs) != e {
+		t.Errorf("got: %s wanted: %s", bs, e)
+	}
+
+	// A nil price is left out
+	if bs, err = json.Marshal(Service{ID: 393}); err != nil {
+		t.Fatal(err)
+	}
+	if e := `{"id":393}`; string(bs) != e {
+		t.Errorf("got: %s wanted: %s", bs, e)
+	}
+}
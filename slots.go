@@ -2,6 +2,7 @@ package makeplans
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -32,14 +33,19 @@ type SlotParams struct {
 	From              time.Time
 	To                time.Time
 	SelectedResources []int
-	OnlyFree          bool
+	// OnlyFree hides slots that have no free capacity left
+	OnlyFree bool
+	// MinimumFreeCount hides slots with fewer free places than the count
+	// needed by the booking. Makeplans only honours it with OnlyFree.
+	MinimumFreeCount int
+	// DayBooking returns one slot per day for services with day booking
+	// enabled instead of slots per interval.
+	DayBooking bool
 }
 
-var SlotURL = "/services/%d/slots" // service_id
-
-// ServiceSlot shows all available slots for a service
-func (c *Client) ServiceSlot(serviceID int, params SlotParams) ([]Slot, error) {
-	path := fmt.Sprintf(SlotURL, serviceID)
+// values encodes the params into the query string understood by the
+// slot endpoints
+func (params SlotParams) values() url.Values {
 	v := url.Values{}
 	layout := "2006-01-02"
 	from := params.From
@@ -57,8 +63,35 @@ func (c *Client) ServiceSlot(serviceID int, params SlotParams) ([]Slot, error) {
 		}
 		v.Add("selected_resources", strings.Join(s, ","))
 	}
+	if params.OnlyFree {
+		v.Set("only_free", "true")
+	}
+	if params.MinimumFreeCount > 0 {
+		v.Set("minimum_free_count", strconv.Itoa(params.MinimumFreeCount))
+	}
+	if params.DayBooking {
+		v.Set("day_booking", "true")
+	}
+	return v
+}
+
+var SlotURL = "/services/%d/slots" // service_id
+
+// ServiceSlot shows all available slots for a service
+func (c *Client) ServiceSlot(serviceID int, params SlotParams) ([]Slot, error) {
+	return c.slots(fmt.Sprintf(SlotURL, serviceID), params)
+}
+
+var ResourceSlotURL = "/resources/%d/slots" // resource_id
+
+// ResourceSlots shows all available slots for a single resource across
+// the services it provides
+func (c *Client) ResourceSlots(resourceID int, params SlotParams) ([]Slot, error) {
+	return c.slots(fmt.Sprintf(ResourceSlotURL, resourceID), params)
+}
 
-	bs, err := c.Do("GET", path+"?"+v.Encode(), nil)
+func (c *Client) slots(path string, params SlotParams) ([]Slot, error) {
+	bs, err := c.Do("GET", path+"?"+params.values().Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -73,30 +106,64 @@ func (c *Client) ServiceSlot(serviceID int, params SlotParams) ([]Slot, error) {
 	return slots, err
 }
 
-var SlotNextDateURL = "/services/%s/next_available_date" // service_id
+var SlotNextDateURL = "/services/%d/next_available_date" // service_id
 
-// SlotNext is the next available slot time for a specified service
-// This doesn't appear to work properly, only one service is ever returned
-func (c *Client) SlotNextDate(serviceID string) ([]Slot, error) {
+// ErrNoAvailableDate is returned when Makeplans has no upcoming date with
+// free slots for the service.
+var ErrNoAvailableDate = errors.New("no available date")
+
+// NextAvailableDate is the next date with free slots for the specified
+// service. From and SelectedResources of params narrow the search. The
+// date is returned as midnight in the account time zone.
+func (c *Client) NextAvailableDate(serviceID int, params SlotParams) (time.Time, error) {
 	path := fmt.Sprintf(SlotNextDateURL, serviceID)
+	// The endpoint only understands from and selected_resources
+	v := SlotParams{
+		From:              params.From,
+		SelectedResources: params.SelectedResources,
+	}.values()
+	if enc := v.Encode(); len(enc) > 0 {
+		path += "?" + enc
+	}
 	bs, err := c.Do("GET", path, nil)
 	if err != nil {
-		return nil, err
+		return time.Time{}, err
 	}
 	wrap := []struct {
 		AvailableDate string `json:"available_date"`
 	}{}
 	// unwrap data structure provided
 	err = json.Unmarshal(bs, &wrap)
-
-	layout := "2006-01-02"
-	slots := make([]Slot, len(wrap))
-	for i, w := range wrap {
-		// json can't unmarshal to ISO8601 shortform, so do it manually
-		t, _ := time.Parse(layout, w.AvailableDate)
-		t = t.AddDate(0, 1, 0)
-		slots[i].Timestamp = &t
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(wrap) == 0 || len(wrap[0].AvailableDate) == 0 {
+		return time.Time{}, ErrNoAvailableDate
 	}
 
-	return slots, err
+	// json can't unmarshal to ISO8601 shortform, so do it manually
+	return time.ParseInLocation("2006-01-02", wrap[0].AvailableDate,
+		c.location())
+}
+
+// NextAvailableSlot returns the first free slot on the next available
+// date for the specified service.
+func (c *Client) NextAvailableSlot(serviceID int, params SlotParams) (Slot, error) {
+	day, err := c.NextAvailableDate(serviceID, params)
+	if err != nil {
+		return Slot{}, err
+	}
+	params.From = day
+	params.To = day
+	params.OnlyFree = true
+	slots, err := c.ServiceSlot(serviceID, params)
+	if err != nil {
+		return Slot{}, err
+	}
+	for _, slot := range slots {
+		if slot.Free > 0 {
+			return slot, nil
+		}
+	}
+	return Slot{}, ErrNoAvailableDate
 }
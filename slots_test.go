@@ -163,14 +163,116 @@ var testSlotNext = []byte(`[
 
 func TestSlot_next(t *testing.T) {
 	_, client := mockServerClient(t)
+	loc := time.FixedZone("CST", -6*60*60)
+	client.Location = loc
 
-	slots, err := client.SlotNextDate("320")
+	day, err := client.NextAvailableDate(320, SlotParams{})
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
+	}
+
+	if e := time.Date(2013, 4, 3, 0, 0, 0, 0, loc); !day.Equal(e) {
+		t.Errorf("got: %s wanted: %s", day, e)
+	}
+
+	if day.Location() != loc {
+		t.Errorf("got: %s wanted: %s", day.Location(), loc)
+	}
+}
+
+var testSlotNextResource = []byte(`[
+    {
+        "available_date": "2015-12-14"
+    }
+]`)
+
+func TestSlot_nextSlot(t *testing.T) {
+	_, client := mockServerClient(t)
+	layout := "2006-01-02"
+
+	from, _ := time.Parse(layout, "2015-12-10")
+	slot, err := client.NextAvailableSlot(427, SlotParams{
+		From:              from,
+		SelectedResources: []int{517},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e := "Monday, December 14, 2015, 1:00 PM"; slot.FormattedTimestamp != e {
+		t.Errorf("got: %s wanted: %s", slot.FormattedTimestamp, e)
+	}
+
+	_, err = client.NextAvailableDate(321, SlotParams{})
+	if err != ErrNoAvailableDate {
+		t.Errorf("got: %v wanted: %s", err, ErrNoAvailableDate)
+	}
+}
+
+var testSlotsFree = []byte(`[
+  {
+    "slot": {
+      "timestamp": "2015-12-14T13:00:00-06:00",
+      "timestamp_end": "2015-12-14T14:00:00-06:00",
+      "formatted_timestamp": "Monday, December 14, 2015, 1:00 PM",
+      "formatted_timestamp_end": "Monday, December 14, 2015, 2:00 PM",
+      "free": 2,
+      "available_resources": [
+        501,
+        517
+      ],
+      "maximum_capacity": 2
+    }
+  }
+]`)
+
+func TestSlot_onlyFree(t *testing.T) {
+	_, client := mockServerClient(t)
+	layout := "2006-01-02"
+
+	from, _ := time.Parse(layout, "2015-12-14")
+	to, _ := time.Parse(layout, "2015-12-15")
+	params := SlotParams{
+		From:             from,
+		To:               to,
+		OnlyFree:         true,
+		MinimumFreeCount: 2,
+	}
+	slots, err := client.ServiceSlot(427, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e := 1; len(slots) != e {
+		t.Fatalf("wrong number of slots returned got: %d wanted: %d",
+			len(slots), e)
+	}
+
+	if e := 2; slots[0].Free != e {
+		t.Errorf("got: %d wanted: %d", slots[0].Free, e)
+	}
+}
+
+func TestSlot_resource(t *testing.T) {
+	_, client := mockServerClient(t)
+	layout := "2006-01-02"
+
+	from, _ := time.Parse(layout, "2015-12-14")
+	to, _ := time.Parse(layout, "2015-12-15")
+	slots, err := client.ResourceSlots(517, SlotParams{
+		From: from,
+		To:   to,
+	})
+	if err != nil {
+		t.Fatal(err)
 	}
 
 	if e := 1; len(slots) != e {
 		t.Fatalf("wrong number of slots returned got: %d wanted: %d",
 			len(slots), e)
 	}
+
+	if e := 517; slots[0].AvailableResources[0] != e {
+		t.Errorf("got: %d wanted: %d", slots[0].AvailableResources[0], e)
+	}
 }
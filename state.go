@@ -0,0 +1,126 @@
+package makeplans
+
+import "fmt"
+
+// BookingState is the lifecycle state of a booking
+type BookingState string
+
+const (
+	// BookingUnverified bookings wait for the person to verify them by
+	// mail or sms
+	BookingUnverified BookingState = "unverified"
+	// BookingAwaitingConfirmation bookings wait for staff to confirm or
+	// decline them
+	BookingAwaitingConfirmation BookingState = "awaiting_confirmation"
+	BookingConfirmed            BookingState = "confirmed"
+	BookingDeclined             BookingState = "declined"
+	BookingCancelled            BookingState = "cancelled"
+	// BookingExpired bookings were not verified in time. Only Makeplans
+	// moves bookings into this state.
+	BookingExpired BookingState = "expired"
+	BookingDeleted BookingState = "deleted"
+)
+
+// Active reports if a booking in this state takes up capacity. Unknown
+// states are assumed active.
+func (s BookingState) Active() bool {
+	switch s {
+	case BookingDeclined, BookingCancelled, BookingExpired, BookingDeleted:
+		return false
+	}
+	return true
+}
+
+// BookingTransition is a state change and the Makeplans action that
+// performs it
+type BookingTransition struct {
+	From   BookingState
+	To     BookingState
+	Action string
+}
+
+// BookingTransitions lists every transition the API can perform.
+//
+//	unverified            -> awaiting_confirmation  verify
+//	unverified            -> confirmed              verify (service without confirmation)
+//	unverified            -> cancelled              cancel
+//	awaiting_confirmation -> confirmed              confirm
+//	awaiting_confirmation -> declined               decline
+//	awaiting_confirmation -> cancelled              cancel
+//	confirmed             -> cancelled              cancel
+//	any but deleted       -> deleted                delete
+//
+// Expired is never a valid target, Makeplans expires bookings itself.
+var BookingTransitions = []BookingTransition{
+	{BookingUnverified, BookingAwaitingConfirmation, "verify"},
+	{BookingUnverified, BookingConfirmed, "verify"},
+	{BookingUnverified, BookingCancelled, "cancel"},
+	{BookingAwaitingConfirmation, BookingConfirmed, "confirm"},
+	{BookingAwaitingConfirmation, BookingDeclined, "decline"},
+	{BookingAwaitingConfirmation, BookingCancelled, "cancel"},
+	{BookingConfirmed, BookingCancelled, "cancel"},
+	{BookingUnverified, BookingDeleted, "delete"},
+	{BookingAwaitingConfirmation, BookingDeleted, "delete"},
+	{BookingConfirmed, BookingDeleted, "delete"},
+	{BookingDeclined, BookingDeleted, "delete"},
+	{BookingCancelled, BookingDeleted, "delete"},
+	{BookingExpired, BookingDeleted, "delete"},
+}
+
+// TransitionError is returned when a booking can not move between two
+// states. Reached is set when the action ran but left the booking in
+// another state.
+type TransitionError struct {
+	ID      int
+	From    BookingState
+	To      BookingState
+	Reached BookingState
+}
+
+func (e TransitionError) Error() string {
+	if len(e.Reached) > 0 {
+		return fmt.Sprintf("booking %d moved from %s to %s, not %s",
+			e.ID, e.From, e.Reached, e.To)
+	}
+	return fmt.Sprintf("booking %d can not transition from %s to %s",
+		e.ID, e.From, e.To)
+}
+
+// Transition returns the action moving a booking from one state to the
+// other. ok is false if the transition is illegal.
+func Transition(from, to BookingState) (action string, ok bool) {
+	for _, t := range BookingTransitions {
+		if t.From == from && t.To == to {
+			return t.Action, true
+		}
+	}
+	return "", false
+}
+
+// TransitionBooking moves the booking to the state passed. The current
+// state is fetched first and illegal transitions are rejected with a
+// TransitionError without calling the action. When the action leaves the
+// booking in another state, ie. verify on a service requiring
+// confirmation, the booking is returned with a TransitionError naming the
+// state reached.
+func (c *Client) TransitionBooking(id int, to BookingState) (Booking, error) {
+	b, err := c.Booking(id)
+	if err != nil {
+		return b, err
+	}
+	if b.ID == 0 {
+		return b, ErrNotFound
+	}
+	action, ok := Transition(b.State, to)
+	if !ok {
+		return b, TransitionError{ID: id, From: b.State, To: to}
+	}
+	ret, err := c.mutateBooking(action, id)
+	if err != nil {
+		return ret, err
+	}
+	if ret.State != to {
+		return ret, TransitionError{ID: id, From: b.State, To: to, Reached: ret.State}
+	}
+	return ret, nil
+}
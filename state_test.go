@@ -0,0 +1,95 @@
+package makeplans
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBookingState_transition(t *testing.T) {
+	if action, ok := Transition(BookingAwaitingConfirmation, BookingConfirmed); !ok || action != "confirm" {
+		t.Errorf("got: %s wanted: confirm", action)
+	}
+
+	if action, ok := Transition(BookingConfirmed, BookingCancelled); !ok || action != "cancel" {
+		t.Errorf("got: %s wanted: cancel", action)
+	}
+
+	illegal := [][2]BookingState{
+		{BookingDeleted, BookingDeleted},
+		{BookingConfirmed, BookingDeclined},
+		{BookingCancelled, BookingConfirmed},
+		{BookingUnverified, BookingExpired},
+	}
+	for _, tr := range illegal {
+		if _, ok := Transition(tr[0], tr[1]); ok {
+			t.Errorf("expected %s -> %s to be illegal", tr[0], tr[1])
+		}
+	}
+
+	if BookingCancelled.Active() || !BookingAwaitingConfirmation.Active() {
+		t.Error("wrong active states")
+	}
+}
+
+func TestBooking_transition(t *testing.T) {
+	_, client := mockServerClient(t)
+	id := 410369
+
+	// bookingOneResponse is deleted, it can't be cancelled
+	_, err := client.TransitionBooking(id, BookingCancelled)
+	te, ok := err.(TransitionError)
+	if !ok {
+		t.Fatalf("got: %v wanted TransitionError", err)
+	}
+
+	if e := BookingDeleted; te.From != e {
+		t.Errorf("got: %s wanted: %s", te.From, e)
+	}
+}
+
+// transitionServer serves an unverified booking 1, verify moves it to
+// reached
+func transitionServer(t *testing.T, reached BookingState) *Client {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := BookingUnverified
+		switch {
+		case r.Method == "PUT" && r.URL.Path == BookingURL+"1/verify":
+			state = reached
+		case r.Method == "GET" && r.URL.Path == BookingURL+"1":
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprintf(w, `{"booking":{"id":1,"state":%q}}`, state)
+	}))
+	t.Cleanup(ts.Close)
+	return &Client{URL: ts.URL, Resolver: testResolver}
+}
+
+func TestBooking_transitionVerify(t *testing.T) {
+	client := transitionServer(t, BookingConfirmed)
+	b, err := client.TransitionBooking(1, BookingConfirmed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := BookingConfirmed; b.State != e {
+		t.Errorf("got: %s wanted: %s", b.State, e)
+	}
+}
+
+func TestBooking_transitionMismatch(t *testing.T) {
+	// The service requires confirmation
+	client := transitionServer(t, BookingAwaitingConfirmation)
+	b, err := client.TransitionBooking(1, BookingConfirmed)
+	te, ok := err.(TransitionError)
+	if !ok {
+		t.Fatalf("got: %v wanted TransitionError", err)
+	}
+	if e := BookingAwaitingConfirmation; te.Reached != e || b.State != e {
+		t.Errorf("got: %s wanted: %s", te.Reached, e)
+	}
+	if e := "booking 1 moved from unverified to awaiting_confirmation, not confirmed"; err.Error() != e {
+		t.Errorf("got: %s\nwanted: %s", err, e)
+	}
+}